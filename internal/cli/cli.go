@@ -7,6 +7,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// SkipConfigValidation is the annotation of the commands that validate the config file themselves
+const SkipConfigValidation = "skip-config-validation"
+
 // NoArgs validates args and returns an error if there are any args
 func NoArgs(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
@@ -1,6 +1,7 @@
 package build
 
 import (
+	"strings"
 	"testing"
 	"tugboat/internal/pkg/flags"
 
@@ -36,7 +37,7 @@ func TestBuildCommand(t *testing.T) {
 	}
 
 	// validate the number of flags
-	expectedFlagCount := 7
+	expectedFlagCount := 20
 	actualFlagCount := 0
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		actualFlagCount++
@@ -74,4 +75,99 @@ func TestBuildCommand(t *testing.T) {
 	if _, err := cmd.Flags().GetBool("no-cache"); err != nil {
 		t.Error(err)
 	}
+
+	if _, err := cmd.Flags().GetString("target"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringSlice("label"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetString("platform"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetString("network"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringSlice("add-host"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetString("shm-size"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringSlice("ulimit"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringArray("ssh"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringArray("secret"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringArray("cache-from"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringArray("cache-to"); err != nil {
+		t.Error(err)
+	}
+
+	// the labels are added unless they are disabled
+	if enabled, err := cmd.Flags().GetBool("oci-labels"); err != nil {
+		t.Error(err)
+	} else if !enabled {
+		t.Error("expected the oci labels to be enabled by default")
+	}
+
+	if _, err := cmd.Flags().GetStringSlice("oci-labels-exclude"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_getLabels(t *testing.T) {
+	opts := &flags.Options{
+		Global: flags.GlobalOptions{
+			Git: flags.Git{
+				FullCommit: "abc123",
+				Remote:     "git@github.com:gotugboat/tugboat.git",
+			},
+		},
+		Build: flags.BuildOptions{
+			Labels: []string{"org.opencontainers.image.title=custom"},
+			OciLabels: flags.OciLabelOptions{
+				Enabled: true,
+				Exclude: []string{"created"},
+			},
+		},
+		Image: flags.ImageOptions{
+			Name:    "image",
+			Version: "1.0.0",
+		},
+	}
+
+	expected := "org.opencontainers.image.source=https://github.com/gotugboat/tugboat " +
+		"org.opencontainers.image.version=1.0.0 " +
+		"org.opencontainers.image.revision=abc123 " +
+		"org.opencontainers.image.ref.name=latest " +
+		"org.opencontainers.image.title=custom"
+	actual := strings.Join(getLabels(opts, []string{"image:latest"}), " ")
+	if expected != actual {
+		t.Errorf("expected '%v', got '%v'", expected, actual)
+	}
+
+	// disabling the labels should only return the user labels
+	opts.Build.OciLabels.Enabled = false
+	expected = "org.opencontainers.image.title=custom"
+	actual = strings.Join(getLabels(opts, []string{"image:latest"}), " ")
+	if expected != actual {
+		t.Errorf("expected '%v', got '%v'", expected, actual)
+	}
 }
@@ -2,13 +2,14 @@ package build
 
 import (
 	"context"
+	"time"
 	"tugboat/internal/cli"
 	"tugboat/internal/driver"
 	"tugboat/internal/drivers"
 	"tugboat/internal/image"
 	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/git"
 	"tugboat/internal/pkg/tmpl"
-	"tugboat/internal/registry"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -19,13 +20,24 @@ func NewBuildCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 	imageFlags := flags.NewImageFlagsGroup()
 
 	cmd := &cobra.Command{
-		Use:   "build",
+		Use:   "build [IMAGE...]",
 		Short: "Build a container",
 		Long:  buildDescription,
-		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := flags.ToOptions(globalFlags, buildFlags, imageFlags)
-			return runBuild(opts)
+			imagesOpts, err := flags.ToImageOptions(globalFlags, args, buildFlags, imageFlags)
+			if err != nil {
+				return err
+			}
+
+			for _, opts := range imagesOpts {
+				if len(imagesOpts) > 1 {
+					log.Infof("Building %s", opts.Image.Name)
+				}
+				if err := runBuild(opts); err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	}
 
@@ -50,16 +62,32 @@ func runBuild(opts *flags.Options) error {
 
 	log.Debugf("compiledTags: %v", compiledTags)
 
-	registry, err := registry.NewRegistry(
-		opts.Global.Registry.Url,
-		opts.Global.Registry.Namespace,
-		opts.Global.Registry.Username,
-		opts.Global.Registry.Password,
-	)
+	registry, err := cli.NewRegistry(opts.Global.Registry)
+	if err != nil {
+		return err
+	}
+
+	// the additional registries are only published to when pushing
+	var mirrorRegistries []flags.RegistryOptions
+	if opts.Build.Push {
+		mirrorRegistries = opts.Global.Registries
+	}
+
+	mirrors, err := cli.NewRegistries(mirrorRegistries)
 	if err != nil {
 		return err
 	}
 
+	if opts.Build.Push && !opts.Global.DryRun {
+		if err := cli.CheckRegistryAuth(ctx, registry); err != nil {
+			return err
+		}
+
+		if err := cli.CheckRegistryAuth(ctx, mirrors...); err != nil {
+			return err
+		}
+	}
+
 	driverOpts := driver.DriverOptions{
 		Registry:        registry,
 		DryRun:          opts.Global.DryRun,
@@ -79,10 +107,57 @@ func runBuild(opts *flags.Options) error {
 		Pull:       opts.Build.Pull,
 		NoCache:    opts.Build.NoCache,
 		Push:       opts.Build.Push,
+		Target:     opts.Build.Target,
+		Labels:     getLabels(opts, compiledTags),
+		Platform:   opts.Build.Platform,
+		Network:    opts.Build.Network,
+		AddHosts:   opts.Build.AddHosts,
+		ShmSize:    opts.Build.ShmSize,
+		Ulimits:    opts.Build.Ulimits,
+		Ssh:        opts.Build.Ssh,
+		Secrets:    opts.Build.Secrets,
+		CacheFrom:  opts.Build.CacheFrom,
+		CacheTo:    opts.Build.CacheTo,
 	}
 	if err := image.Build(ctx, d, buildOpts); err != nil {
 		return err
 	}
 
+	if len(mirrors) > 0 {
+		mirrorOpts := image.MirrorOptions{
+			Images:          compiledTags,
+			Official:        opts.Global.Official,
+			ArchitectureTag: flags.DefaultArchOption,
+			DryRun:          opts.Global.DryRun,
+		}
+		if err := image.Mirror(ctx, registry, mirrors, mirrorOpts); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// getLabels combines the standard OCI labels with the labels provided by the user
+func getLabels(opts *flags.Options, compiledTags []string) []string {
+	if !opts.Build.OciLabels.Enabled {
+		return opts.Build.Labels
+	}
+
+	var refName string
+	if len(compiledTags) > 0 {
+		refName = image.RefName(compiledTags[0])
+	}
+
+	ociLabels := image.OciLabels(image.OciLabelOptions{
+		Created:  time.Now(),
+		Source:   git.SourceUrl(opts.Global.Git.Remote),
+		Version:  opts.Image.Version,
+		Revision: opts.Global.Git.FullCommit,
+		RefName:  refName,
+		Title:    opts.Image.Name,
+		Exclude:  opts.Build.OciLabels.Exclude,
+	})
+
+	return image.MergeLabels(ociLabels, opts.Build.Labels)
+}
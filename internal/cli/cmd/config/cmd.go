@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"tugboat/internal/cli"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+func NewConfigCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config COMMAND",
+		Short: "Manage the configuration file",
+		Long:  configDescription,
+		Args:  cli.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(cmd.UsageString())
+		},
+		// the config commands report the problems of the config file themselves
+		Annotations: map[string]string{cli.SkipConfigValidation: "true"},
+	}
+
+	cmd.AddCommand(
+		newInitCommand(globalFlags),
+		newMigrateCommand(globalFlags),
+		newShowCommand(globalFlags),
+		newValidateCommand(globalFlags),
+	)
+
+	return cmd
+}
+
+var configDescription = `Manage the configuration file`
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"tugboat/internal/cli"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/spf13/pflag"
+)
+
+func TestNewConfigCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := NewConfigCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Manage the configuration file"
+	if expected != cmd.Long {
+		t.Errorf("expected %v, got %v", expected, cmd.Long)
+	}
+
+	expected = "Manage the configuration file"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate the number of commands attached to this command
+	commands := cmd.Commands()
+	expectedCommands := 4
+	actualCommands := len(commands)
+	if actualCommands != expectedCommands {
+		t.Errorf("expected commands %v, got %v", expectedCommands, actualCommands)
+	}
+
+	// validate the number of flags
+	expectedFlagCount := 0
+	actualFlagCount := 0
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		actualFlagCount++
+	})
+
+	if actualFlagCount != expectedFlagCount {
+		t.Errorf("expected %v flags, got %v", expectedFlagCount, actualFlagCount)
+	}
+
+	// the config commands validate the config file themselves
+	if _, ok := cmd.Annotations[cli.SkipConfigValidation]; !ok {
+		t.Errorf("expected the %v annotation", cli.SkipConfigValidation)
+	}
+}
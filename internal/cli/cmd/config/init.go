@@ -0,0 +1,281 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"tugboat/internal/cli"
+	"tugboat/internal/config"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/git"
+	"tugboat/internal/pkg/reference/distribution/reference"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// defaultArchitectures are suggested when the architectures of the base images are unknown
+var defaultArchitectures = []string{"amd64", "arm64"}
+
+// stdin is where the answers of the interactive prompts are read from
+var stdin io.Reader = os.Stdin
+
+// lookupPlatforms returns the platforms of a base image, it is replaced in the tests
+var lookupPlatforms = basePlatforms
+
+func newInitCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	initFlags := flags.NewConfigInitFlagGroup()
+
+	cmd := &cobra.Command{
+		Use:   "init [DIRECTORY]",
+		Short: "Create a configuration file for the project in the working directory",
+		Long:  initDescription,
+		Args:  cli.RequiresMaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags, initFlags)
+			if err != nil {
+				return err
+			}
+			return runInit(opts, args)
+		},
+	}
+
+	flags.AddFlags(cmd, initFlags)
+	flags.Bind(cmd, initFlags)
+
+	return cmd
+}
+
+var initDescription = `Create a commented configuration file by inspecting the project: the Dockerfiles are found in the
+directory, the namespace and image name are suggested from the git remote and the supported
+architectures are the platforms of the base images. Use --interactive to review each value`
+
+func runInit(opts *flags.Options, args []string) error {
+	log.Debugf("Config Init Options: %+v", opts)
+	log.Debugf("Config Init Args: %+v", args)
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	output := opts.Config.Init.Output
+	if !filepath.IsAbs(output) && len(args) > 0 {
+		output = filepath.Join(dir, output)
+	}
+
+	if _, err := os.Stat(output); err == nil && !opts.Config.Init.Force {
+		return errors.Errorf("%s already exists, use --force to overwrite it", output)
+	}
+
+	values, err := initValues(opts, dir)
+	if err != nil {
+		return err
+	}
+
+	if opts.Config.Init.Interactive {
+		if err := promptValues(values, stdin, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	content, err := config.RenderConfig(*values)
+	if err != nil {
+		return err
+	}
+
+	if opts.Global.DryRun {
+		fmt.Print(string(content))
+		return nil
+	}
+
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return errors.Wrapf(err, "writing %s", output)
+	}
+
+	fmt.Printf("Created %s\n", output)
+	return nil
+}
+
+// initValues returns the values of the configuration file, the flags take precedence over what
+// is detected in the project
+func initValues(opts *flags.Options, dir string) (*config.InitValues, error) {
+	project, err := config.InspectProject(dir, projectRemote(context.Background(), dir))
+	if err != nil {
+		return nil, err
+	}
+
+	values := &config.InitValues{
+		Registry:      opts.Global.Registry.Url,
+		Namespace:     project.Namespace,
+		ImageName:     project.ImageName,
+		Dockerfile:    "Dockerfile",
+		Context:       ".",
+		Architectures: opts.Config.Init.Architectures,
+	}
+
+	if opts.Global.Registry.Namespace != "" {
+		values.Namespace = opts.Global.Registry.Namespace
+	}
+	if opts.Config.Init.Name != "" {
+		values.ImageName = opts.Config.Init.Name
+	}
+
+	switch {
+	case opts.Config.Init.File != "":
+		values.Dockerfile = opts.Config.Init.File
+	case len(project.Dockerfiles) > 0:
+		values.Dockerfile = project.Dockerfiles[0]
+		if len(project.Dockerfiles) > 1 {
+			log.Infof("Found %d Dockerfiles, using %s", len(project.Dockerfiles), values.Dockerfile)
+		}
+	default:
+		log.Warnf("No Dockerfile was found in %s", dir)
+	}
+
+	if len(values.Architectures) == 0 {
+		values.Architectures = detectArchitectures(project)
+	}
+
+	return values, nil
+}
+
+// projectRemote returns the origin remote of the project in the directory without its credentials,
+// an empty string when the directory has no remote
+func projectRemote(ctx context.Context, dir string) string {
+	remote, err := git.Clean(git.RunWithEnv(ctx, []string{}, "-C", dir, "config", "--get", "remote.origin.url"))
+	if err != nil {
+		return ""
+	}
+	return git.StripCredentials(remote)
+}
+
+// detectArchitectures returns the architectures all the base images are available for, the
+// platforms of the FROM instructions are used when the base images cannot be inspected
+func detectArchitectures(project *config.Project) []string {
+	var architectures []string
+	for i, image := range project.BaseImages {
+		platforms, err := lookupPlatforms(context.Background(), image)
+		if err != nil {
+			log.Warnf("Unable to read the platforms of %s: %v", image, err)
+			architectures = nil
+			break
+		}
+
+		var available []string
+		for _, platform := range platforms {
+			if platform.OS == "linux" && !contains(available, platform.Architecture) {
+				available = append(available, platform.Architecture)
+			}
+		}
+
+		if i == 0 {
+			architectures = available
+		} else {
+			architectures = intersect(architectures, available)
+		}
+	}
+
+	if len(architectures) == 0 {
+		for _, platform := range project.Platforms {
+			// linux/arm/v7 -> arm
+			parts := strings.Split(platform, "/")
+			if len(parts) > 1 && !contains(architectures, parts[1]) {
+				architectures = append(architectures, parts[1])
+			}
+		}
+	}
+
+	if len(architectures) == 0 {
+		return defaultArchitectures
+	}
+	return architectures
+}
+
+// basePlatforms reads the platforms of an image with anonymous access to its registry
+func basePlatforms(ctx context.Context, image string) ([]registry.Platform, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := "latest"
+	if digested, ok := named.(reference.Digested); ok {
+		ref = digested.Digest().String()
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		ref = tagged.Tag()
+	}
+
+	c := registry.NewClient(reference.Domain(named), nil)
+	return c.Platforms(ctx, reference.Path(named), ref)
+}
+
+// promptValues asks for each value, an empty answer keeps the suggested value
+func promptValues(values *config.InitValues, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	prompt := func(question string, value string) (string, error) {
+		fmt.Fprintf(out, "%s [%s]: ", question, value)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", errors.Wrap(err, "reading the answer")
+			}
+			return value, nil
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			return answer, nil
+		}
+		return value, nil
+	}
+
+	var err error
+	if values.Registry, err = prompt("Registry", values.Registry); err != nil {
+		return err
+	}
+	if values.Namespace, err = prompt("Namespace", values.Namespace); err != nil {
+		return err
+	}
+	if values.ImageName, err = prompt("Image name", values.ImageName); err != nil {
+		return err
+	}
+	if values.Dockerfile, err = prompt("Dockerfile", values.Dockerfile); err != nil {
+		return err
+	}
+
+	architectures, err := prompt("Supported architectures", strings.Join(values.Architectures, ","))
+	if err != nil {
+		return err
+	}
+	values.Architectures = nil
+	for _, architecture := range strings.Split(architectures, ",") {
+		if architecture = strings.TrimSpace(architecture); architecture != "" {
+			values.Architectures = append(values.Architectures, architecture)
+		}
+	}
+
+	return nil
+}
+
+func intersect(a []string, b []string) []string {
+	var result []string
+	for _, value := range a {
+		if contains(b, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func contains(slice []string, value string) bool {
+	for _, element := range slice {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"tugboat/internal/cli"
+	"tugboat/internal/config"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate [FILE]",
+		Short: "Update the configuration file to the current layout",
+		Long:  migrateDescription,
+		Args:  cli.RequiresMaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags)
+			if err != nil {
+				return err
+			}
+			return migrateConfig(opts, args, os.Stdout)
+		},
+	}
+
+	return cmd
+}
+
+var migrateDescription = `Update the configuration file to the current layout, the keys that were renamed are moved and
+the version key is set. The comments are kept. Use --dry-run to print the updated file instead`
+
+func migrateConfig(opts *flags.Options, args []string, out io.Writer) error {
+	log.Debugf("Config Migrate Options: %+v", opts)
+	log.Debugf("Config Migrate Args: %+v", args)
+
+	file := config.ConfigFileUsed()
+	if len(args) > 0 {
+		file = args[0]
+	}
+
+	if file == "" {
+		return errors.New("no configuration file was found, provide the file to migrate")
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return errors.Wrap(err, "reading the config file")
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return errors.Wrap(err, "reading the config file")
+	}
+
+	migrated, version, err := config.Migrate(content)
+	if err != nil {
+		return errors.Wrap(err, file)
+	}
+
+	if version == config.ConfigVersion {
+		fmt.Fprintf(out, "%s already has the version %d layout\n", file, version)
+		return nil
+	}
+
+	if opts.Global.DryRun {
+		_, err := out.Write(migrated)
+		return err
+	}
+
+	if err := os.WriteFile(file, migrated, info.Mode().Perm()); err != nil {
+		return errors.Wrap(err, "writing the config file")
+	}
+
+	fmt.Fprintf(out, "%s was migrated from version %d to version %d\n", file, version, config.ConfigVersion)
+	return nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/flags"
+)
+
+func Test_newMigrateCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := newMigrateCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Update the configuration file to the current layout"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate what flags are attached to this command
+	if ok := cmd.HasLocalFlags(); ok {
+		t.Error("expected no flags, but there are flags")
+	}
+}
+
+func Test_migrateConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tugboat.yaml")
+	content := "publish:\n  official: true\n"
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// a dry run prints the migrated file
+	var out bytes.Buffer
+	if err := migrateConfig(&flags.Options{Global: flags.GlobalOptions{DryRun: true}}, []string{file}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "official: true") {
+		t.Errorf("expected the migrated file to be printed, got %q", out.String())
+	}
+	if actual, _ := os.ReadFile(file); string(actual) != content {
+		t.Errorf("expected the file to be unchanged by a dry run, got %q", actual)
+	}
+
+	out.Reset()
+	if err := migrateConfig(&flags.Options{}, []string{file}, &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "version: 2\noptions:\n  official: true\n"
+	if actual, _ := os.ReadFile(file); string(actual) != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	out.Reset()
+	if err := migrateConfig(&flags.Options{}, []string{file}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "already has the version 2 layout") {
+		t.Errorf("expected the file to be current, got %q", out.String())
+	}
+}
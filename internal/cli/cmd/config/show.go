@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"tugboat/internal/cli"
+	"tugboat/internal/config"
+	"tugboat/internal/pkg/flags"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newShowCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration and where each value comes from",
+		Long:  showDescription,
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags)
+			if err != nil {
+				return err
+			}
+			return showConfig(opts, os.Stdout)
+		},
+	}
+
+	return cmd
+}
+
+var showDescription = `Show the value of each key after the flags, environment variables, profiles, configuration file
+and defaults are merged, along with the source of the value. The passwords and secrets are redacted`
+
+func showConfig(opts *flags.Options, out io.Writer) error {
+	log.Debugf("Config Show Options: %+v", opts)
+
+	configFile := config.ConfigFileUsed()
+	if configFile == "" {
+		configFile = "none"
+	}
+	fmt.Fprintf(out, "Config file: %s\n", configFile)
+
+	if profiles := config.AppliedProfiles(); len(profiles) > 0 {
+		fmt.Fprintf(out, "Profiles: %s\n", strings.Join(profiles, ", "))
+	}
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, flag := range configFlags() {
+		value := flags.RedactValue(flag.ConfigName, viper.Get(flag.ConfigName))
+		fmt.Fprintf(w, "%s\t%s\t%s\n", flag.ConfigName, formatValue(value), config.ValueSource(flag))
+	}
+
+	return w.Flush()
+}
+
+// configFlags returns the flags with a key in the configuration file, sorted by key
+func configFlags() []*flags.Flag {
+	seen := make(map[string]bool)
+	var configFlags []*flags.Flag
+	for _, group := range flags.AllFlagGroups() {
+		for _, flag := range group.Flags() {
+			if flag == nil || flag.ConfigName == "" || seen[flag.ConfigName] {
+				continue
+			}
+			seen[flag.ConfigName] = true
+			configFlags = append(configFlags, flag)
+		}
+	}
+
+	sort.Slice(configFlags, func(i, j int) bool {
+		return configFlags[i].ConfigName < configFlags[j].ConfigName
+	})
+	return configFlags
+}
+
+// formatValue shows lists and maps as json, so an empty string and an empty list can be told apart
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return `""`
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return v
+	case []interface{}, []string, map[string]interface{}:
+		content, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(content)
+	}
+	return fmt.Sprintf("%v", value)
+}
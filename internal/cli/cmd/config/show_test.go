@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Test_newShowCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := newShowCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Show the effective configuration and where each value comes from"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate what flags are attached to this command
+	if ok := cmd.HasLocalFlags(); ok {
+		t.Error("expected no flags, but there are flags")
+	}
+}
+
+func Test_showConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("TUGBOAT_REGISTRY_NAMESPACE", "from-env")
+
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := &cobra.Command{Use: "tugboat"}
+	flags.AddFlags(cmd, globalFlags)
+	flags.Bind(cmd, globalFlags)
+	if err := cmd.ParseFlags([]string{"--registry", "ghcr.io"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config := `
+registry:
+  url: docker.io
+  user: robot
+  password: hunter2
+  credential-provider:
+    command: ecr-credential-provider
+    env:
+      - AWS_SECRET_ACCESS_KEY=hunter4
+registries:
+  - url: quay.io
+    password: hunter3
+`
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := showConfig(&flags.Options{}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make(map[string]string)
+	for _, line := range strings.Split(out.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines[fields[0]] = strings.Join(fields[1:], " ")
+		}
+	}
+
+	expected := map[string]string{
+		"registry.url":       "ghcr.io flag --registry",
+		"registry.namespace": "from-env env TUGBOAT_REGISTRY_NAMESPACE",
+		"registry.user":      "robot file",
+		"registry.password":  "[REDACTED] file",
+		"options.dry-run":    "false default",
+	}
+	for key, value := range expected {
+		if lines[key] != value {
+			t.Errorf("expected %v to be %q, got %q", key, value, lines[key])
+		}
+	}
+
+	for _, secret := range []string{"hunter2", "hunter3", "hunter4"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("expected %v to be redacted\n%s", secret, out.String())
+		}
+	}
+}
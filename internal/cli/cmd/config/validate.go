@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"tugboat/internal/cli"
+	"tugboat/internal/config"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newValidateCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [FILE]",
+		Short: "Check the configuration file for unknown keys and invalid values",
+		Args:  cli.RequiresMaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags)
+			if err != nil {
+				return err
+			}
+			return validateConfig(opts, args)
+		},
+	}
+
+	return cmd
+}
+
+func validateConfig(opts *flags.Options, args []string) error {
+	log.Debugf("Config Validate Options: %+v", opts)
+	log.Debugf("Config Validate Args: %+v", args)
+
+	file := config.ConfigFileUsed()
+	if len(args) > 0 {
+		file = args[0]
+	}
+
+	if file == "" {
+		return errors.New("no configuration file was found, provide the file to validate")
+	}
+
+	if err := config.Validate(file); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is valid\n", file)
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"tugboat/internal/pkg/flags"
+)
+
+func Test_newValidateCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := newValidateCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Check the configuration file for unknown keys and invalid values"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate what flags are attached to this command
+	if ok := cmd.HasLocalFlags(); ok {
+		t.Error("expected no flags, but there are flags")
+	}
+}
+
+func Test_validateConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := os.WriteFile(valid, []byte("image:\n  name: app\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("image:\n  nme: app\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &flags.Options{}
+
+	if err := validateConfig(opts, []string{valid}); err != nil {
+		t.Errorf("An unexpected error occurred: %v", err)
+	}
+
+	if err := validateConfig(opts, []string{invalid}); err == nil {
+		t.Error("expected an error, but there was none")
+	}
+
+	if err := validateConfig(opts, []string{filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("expected an error, but there was none")
+	}
+}
@@ -0,0 +1,96 @@
+package login
+
+import (
+	"context"
+	"tugboat/internal/cli"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewLoginCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login [SERVER]",
+		Short: "Log in to a registry",
+		Long:  loginDescription,
+		Args:  cli.RequiresMaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags)
+			if err != nil {
+				return err
+			}
+			return runLogin(opts, args)
+		},
+	}
+
+	return cmd
+}
+
+var loginDescription = `Validate the registry credentials and store them the same way 'docker login' does. The stored credentials are used when no registry credentials are configured.`
+
+func runLogin(opts *flags.Options, args []string) error {
+	log.Debugf("Login Options: %+v", opts)
+	log.Debugf("Login Args: %+v", args)
+
+	ctx := context.Background()
+
+	server := opts.Global.Registry.Url
+	if len(args) > 0 {
+		server = args[0]
+	}
+
+	if server == "" {
+		return errors.New("a registry must be provided")
+	}
+
+	registryOpts, err := loginRegistry(opts.Global, server)
+	if err != nil {
+		return err
+	}
+
+	registryOpts, err = cli.ResolveRegistryCredentials(registryOpts)
+	if err != nil {
+		return err
+	}
+
+	if registryOpts.Username == "" || registryOpts.Password == "" {
+		return errors.New("a registry username and password must be provided")
+	}
+
+	user := &registry.RegistryUser{
+		Name:     registryOpts.Username,
+		Password: registryOpts.Password,
+	}
+
+	log.Infof("Logging into %v as %v", server, user.Name)
+
+	if opts.Global.DryRun {
+		return nil
+	}
+
+	if err := registry.NewClient(server, &registry.ClientOptions{User: user, TLS: cli.TLSOptions(registryOpts)}).Ping(ctx); err != nil {
+		return errors.Wrapf(err, "logging into %v", server)
+	}
+
+	if err := registry.StoreCredentials(server, user); err != nil {
+		return errors.Wrapf(err, "storing the credentials for %v", server)
+	}
+
+	log.Info("Login succeeded")
+
+	return nil
+}
+
+// loginRegistry returns the options of the configured registry the server is, so the credentials
+// and tls options of a registry are never sent to another server
+func loginRegistry(opts flags.GlobalOptions, server string) (flags.RegistryOptions, error) {
+	for _, registryOpts := range append([]flags.RegistryOptions{opts.Registry}, opts.Registries...) {
+		if registryOpts.Url != "" && registry.SameServer(registryOpts.Url, server) {
+			return registryOpts, nil
+		}
+	}
+	return flags.RegistryOptions{}, errors.Errorf("no credentials are configured for %v, set it as the registry url or add it to the registries", server)
+}
@@ -0,0 +1,147 @@
+package login
+
+import (
+	"errors"
+	"testing"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/registry"
+	"tugboat/internal/registry/registrytest"
+
+	"github.com/spf13/pflag"
+)
+
+func TestLoginCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := NewLoginCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Validate the registry credentials and store them the same way 'docker login' does. The stored credentials are used when no registry credentials are configured."
+	if expected != cmd.Long {
+		t.Errorf("expected %v, got %v", expected, cmd.Long)
+	}
+
+	expected = "Log in to a registry"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate the number of commands attached to this command
+	commands := cmd.Commands()
+	expectedCommands := 0
+	actualCommands := len(commands)
+	if actualCommands != expectedCommands {
+		t.Errorf("expected commands %v, got %v", expectedCommands, actualCommands)
+	}
+
+	// validate the number of flags
+	expectedFlagCount := 0
+	actualFlagCount := 0
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		actualFlagCount++
+	})
+
+	if actualFlagCount != expectedFlagCount {
+		t.Errorf("expected %v flags, got %v", expectedFlagCount, actualFlagCount)
+	}
+}
+
+func Test_runLogin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		expected error
+		stored   bool
+	}{
+		{name: "valid credentials", password: "password", stored: true},
+		{name: "invalid credentials", password: "wrong", expected: registry.ErrUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DOCKER_CONFIG", t.TempDir())
+			fake := registrytest.New(t, "basic", &registrytest.User{Name: "user", Password: "password"})
+
+			opts := &flags.Options{Global: flags.GlobalOptions{
+				Registry: flags.RegistryOptions{Url: fake.Host(), Username: "user", Password: tc.password, Insecure: true},
+			}}
+
+			if err := runLogin(opts, nil); !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+
+			// the credentials are checked against the registry before they are stored
+			if count := fake.CountRequests("GET /v2/"); count == 0 {
+				t.Error("expected the registry to be pinged")
+			}
+
+			user, err := registry.LookupCredentials(fake.Host())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (user != nil) != tc.stored {
+				t.Errorf("expected the credentials to be stored %v, got %+v", tc.stored, user)
+			}
+		})
+	}
+}
+
+func Test_runLogin_otherServer(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	fake := registrytest.New(t, "basic", &registrytest.User{Name: "robot", Password: "secret"})
+	other := registrytest.New(t, "basic", &registrytest.User{Name: "user", Password: "password"})
+
+	opts := &flags.Options{Global: flags.GlobalOptions{
+		Registry: flags.RegistryOptions{Url: fake.Host(), Username: "robot", Password: "secret", Insecure: true},
+	}}
+
+	// the credentials of the registry are never sent to another server
+	if err := runLogin(opts, []string{other.Host()}); err == nil {
+		t.Error("expected an error for a server without credentials")
+	}
+	if count := other.CountRequests(""); count != 0 {
+		t.Errorf("expected no request to the other server, got %v", count)
+	}
+
+	// the credentials of the additional registry are used for it
+	opts.Global.Registries = []flags.RegistryOptions{
+		{Url: other.Host(), Username: "user", Password: "password", Insecure: true},
+	}
+	if err := runLogin(opts, []string{other.Host()}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := registry.LookupCredentials(other.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || user.Name != "user" {
+		t.Errorf("expected the credentials of user to be stored, got %+v", user)
+	}
+	if count := fake.CountRequests(""); count != 0 {
+		t.Errorf("expected no request to the registry, got %v", count)
+	}
+}
+
+func Test_loginRegistry(t *testing.T) {
+	opts := flags.GlobalOptions{
+		Registry:   flags.RegistryOptions{Url: "docker.io", Username: "robot"},
+		Registries: []flags.RegistryOptions{{Url: "https://ghcr.io", Username: "octocat"}},
+	}
+
+	testCases := map[string]string{
+		"index.docker.io": "robot",
+		"ghcr.io":         "octocat",
+	}
+	for server, expected := range testCases {
+		registryOpts, err := loginRegistry(opts, server)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if registryOpts.Username != expected {
+			t.Errorf("%v: expected %v, got %v", server, expected, registryOpts.Username)
+		}
+	}
+
+	if _, err := loginRegistry(opts, "other.io"); err == nil {
+		t.Error("expected an error for other.io")
+	}
+}
@@ -0,0 +1,57 @@
+package logout
+
+import (
+	"tugboat/internal/cli"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewLogoutCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout [SERVER]",
+		Short: "Log out from a registry",
+		Long:  logoutDescription,
+		Args:  cli.RequiresMaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags)
+			if err != nil {
+				return err
+			}
+			return runLogout(opts, args)
+		},
+	}
+
+	return cmd
+}
+
+var logoutDescription = `Remove the credentials stored for a registry by 'tugboat login' or 'docker login'`
+
+func runLogout(opts *flags.Options, args []string) error {
+	log.Debugf("Logout Options: %+v", opts)
+	log.Debugf("Logout Args: %+v", args)
+
+	server := opts.Global.Registry.Url
+	if len(args) > 0 {
+		server = args[0]
+	}
+
+	if server == "" {
+		return errors.New("a registry must be provided")
+	}
+
+	log.Infof("Removing the credentials for %v", server)
+
+	if opts.Global.DryRun {
+		return nil
+	}
+
+	if err := registry.EraseCredentials(server); err != nil {
+		return errors.Wrapf(err, "removing the credentials for %v", server)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package logout
+
+import (
+	"testing"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/registry"
+
+	"github.com/spf13/pflag"
+)
+
+func TestLogoutCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := NewLogoutCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Remove the credentials stored for a registry by 'tugboat login' or 'docker login'"
+	if expected != cmd.Long {
+		t.Errorf("expected %v, got %v", expected, cmd.Long)
+	}
+
+	expected = "Log out from a registry"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate the number of commands attached to this command
+	commands := cmd.Commands()
+	expectedCommands := 0
+	actualCommands := len(commands)
+	if actualCommands != expectedCommands {
+		t.Errorf("expected commands %v, got %v", expectedCommands, actualCommands)
+	}
+
+	// validate the number of flags
+	expectedFlagCount := 0
+	actualFlagCount := 0
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		actualFlagCount++
+	})
+
+	if actualFlagCount != expectedFlagCount {
+		t.Errorf("expected %v flags, got %v", expectedFlagCount, actualFlagCount)
+	}
+}
+
+func Test_runLogout(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	for _, server := range []string{"ghcr.io", "quay.io"} {
+		if err := registry.StoreCredentials(server, &registry.RegistryUser{Name: "user", Password: "password"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &flags.Options{Global: flags.GlobalOptions{Registry: flags.RegistryOptions{Url: "ghcr.io"}}}
+	if err := runLogout(opts, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the entry of the registry is removed
+	if user, err := registry.LookupCredentials("ghcr.io"); err != nil || user != nil {
+		t.Errorf("expected the credentials of ghcr.io to be removed, got %+v %v", user, err)
+	}
+	if user, err := registry.LookupCredentials("quay.io"); err != nil || user == nil {
+		t.Errorf("expected the credentials of quay.io to be kept, got %+v %v", user, err)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"tugboat/internal/cli"
 	"tugboat/internal/driver"
 	"tugboat/internal/drivers"
+	"tugboat/internal/image"
 	"tugboat/internal/manifest"
 	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/reference"
 	"tugboat/internal/pkg/tmpl"
-	"tugboat/internal/registry"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -19,12 +20,24 @@ func newCreateCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 	imageFlags := flags.NewImageFlagsGroup()
 
 	cmd := &cobra.Command{
-		Use:   "create IMAGE",
+		Use:   "create MANIFEST_LIST [IMAGE...]",
 		Short: "Create a local annotated manifest list for pushing to a registry",
-		Args:  cli.ExactArgs(1),
+		Args:  cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := flags.ToOptions(globalFlags, manifestCreateFlags, imageFlags)
-			return createManifest(opts, args)
+			imagesOpts, err := flags.ToImageOptions(globalFlags, args[1:], manifestCreateFlags, imageFlags)
+			if err != nil {
+				return err
+			}
+
+			for _, opts := range imagesOpts {
+				if len(imagesOpts) > 1 {
+					log.Infof("Creating the manifest lists of %s", opts.Image.Name)
+				}
+				if err := createManifest(opts, args[:1]); err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	}
 
@@ -51,16 +64,32 @@ func createManifest(opts *flags.Options, args []string) error {
 		return err
 	}
 
-	registry, err := registry.NewRegistry(
-		opts.Global.Registry.Url,
-		opts.Global.Registry.Namespace,
-		opts.Global.Registry.Username,
-		opts.Global.Registry.Password,
-	)
+	registry, err := cli.NewRegistry(opts.Global.Registry)
 	if err != nil {
 		return err
 	}
 
+	// the additional registries are only published to when pushing
+	var mirrorRegistries []flags.RegistryOptions
+	if opts.Manifest.Create.Push {
+		mirrorRegistries = opts.Global.Registries
+	}
+
+	mirrors, err := cli.NewRegistries(mirrorRegistries)
+	if err != nil {
+		return err
+	}
+
+	if opts.Manifest.Create.Push && !opts.Global.DryRun {
+		if err := cli.CheckRegistryAuth(ctx, registry); err != nil {
+			return err
+		}
+
+		if err := cli.CheckRegistryAuth(ctx, mirrors...); err != nil {
+			return err
+		}
+	}
+
 	driverOpts := driver.DriverOptions{
 		Registry:        registry,
 		DryRun:          opts.Global.DryRun,
@@ -82,6 +111,19 @@ func createManifest(opts *flags.Options, args []string) error {
 	if err := manifest.Create(ctx, d, manifestCreateOpts); err != nil {
 		return err
 	}
+
+	if len(mirrors) > 0 {
+		// the manifest lists are copied along with every image they refer to
+		mirrorOpts := image.MirrorOptions{
+			Images:          image.TaggedImages(compiledManifestList, manifestTags),
+			Official:        opts.Global.Official,
+			ArchitectureTag: string(reference.ArchOmit),
+			DryRun:          opts.Global.DryRun,
+		}
+		if err := image.Mirror(ctx, registry, mirrors, mirrorOpts); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
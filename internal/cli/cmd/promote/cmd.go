@@ -0,0 +1,170 @@
+package promote
+
+import (
+	"context"
+	"strings"
+	"tugboat/internal/cli"
+	"tugboat/internal/driver"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/reference"
+	"tugboat/internal/pkg/tmpl"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewPromoteCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	promoteFlags := flags.NewPromoteFlagGroup()
+	imageFlags := flags.NewImageFlagsGroup()
+
+	cmd := &cobra.Command{
+		Use:   "promote SOURCE_IMAGE TARGET_IMAGE",
+		Short: "Copy an image from one registry to another",
+		Long:  promoteDescription,
+		Args:  cli.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags, promoteFlags, imageFlags)
+			if err != nil {
+				return err
+			}
+			return runPromote(opts, args)
+		},
+	}
+
+	flags.AddFlags(cmd, promoteFlags)
+	flags.Bind(cmd, promoteFlags)
+
+	return cmd
+}
+
+var promoteDescription = `Copy an image, including every architecture of a manifest list, from one registry to another while preserving the digests`
+
+func runPromote(opts *flags.Options, args []string) error {
+	log.Debugf("Promote Options: %+v", opts)
+	log.Debugf("Promote Args: %+v", args)
+
+	ctx := context.Background()
+
+	compiledSourceImage, err := tmpl.CompileString(args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	compiledTargetImage, err := tmpl.CompileString(args[1], opts)
+	if err != nil {
+		return err
+	}
+
+	sourceUri, err := driver.GenerateUri(opts.Global.Registry.Url, opts.Global.Registry.Namespace, compiledSourceImage, false, reference.ArchOmit)
+	if err != nil {
+		return err
+	}
+
+	targetUri, err := driver.GenerateUri(opts.Global.Registry.Url, opts.Global.Registry.Namespace, compiledTargetImage, false, reference.ArchOmit)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Promoting %s to %s", sourceUri.Remote(), targetUri.Remote())
+
+	if opts.Global.DryRun {
+		return nil
+	}
+
+	registryOpts, err := cli.ResolveRegistryCredentials(opts.Global.Registry)
+	if err != nil {
+		return err
+	}
+
+	sourceUser, err := getUser(opts.Promote.Source, registryOpts, sourceUri.Registry())
+	if err != nil {
+		return err
+	}
+
+	targetUser, err := getUser(opts.Promote.Target, registryOpts, targetUri.Registry())
+	if err != nil {
+		return err
+	}
+
+	source := registry.ImageLocation{
+		Client: registry.NewClient(sourceUri.Registry(), &registry.ClientOptions{
+			User: sourceUser,
+			TLS:  getTLSOptions(registryOpts, sourceUri.Registry()),
+		}),
+		Repository: sourceUri.ShortName(),
+		Reference:  sourceUri.Tag(),
+	}
+
+	target := registry.ImageLocation{
+		Client: registry.NewClient(targetUri.Registry(), &registry.ClientOptions{
+			User: targetUser,
+			TLS:  getTLSOptions(registryOpts, targetUri.Registry()),
+		}),
+		Repository: targetUri.ShortName(),
+		Reference:  targetUri.Tag(),
+	}
+
+	if err := target.Client.Ping(ctx); err != nil {
+		return errors.Wrapf(err, "checking the credentials for %v", targetUri.Registry())
+	}
+
+	dgst, err := registry.CopyImage(ctx, source, target)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Promoted %s@%s", targetUri.Remote(), dgst)
+
+	return nil
+}
+
+// getUser returns the credentials to use for one side of the promotion. The registry credentials
+// are only used for the configured registry, the docker credentials stored for the host are used
+// otherwise. Nil is returned for anonymous access.
+func getUser(credentials flags.CredentialOptions, registryOpts flags.RegistryOptions, host string) (*registry.RegistryUser, error) {
+	if credentials.Username == "" && credentials.Password == "" {
+		if registryHost(registryOpts) != host || registryOpts.Username == "" {
+			return registry.LookupCredentials(host)
+		}
+		return &registry.RegistryUser{
+			Name:     registryOpts.Username,
+			Password: registryOpts.Password,
+		}, nil
+	}
+
+	// the password is read from the environment or a file so it is not exposed on the command line
+	if !registry.IsSecretReference(credentials.Password) {
+		return nil, errors.Errorf("the password for %s must be read from an environment variable (env:VAR) or a file (file:PATH)", host)
+	}
+
+	username, err := registry.ResolveSecret(credentials.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := registry.ResolveSecret(credentials.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &registry.RegistryUser{
+		Name:     username,
+		Password: password,
+	}, nil
+}
+
+// getTLSOptions returns the tls options of the registry when the host is the configured registry,
+// other registries are reached with the default options
+func getTLSOptions(registryOpts flags.RegistryOptions, host string) registry.TLSOptions {
+	if registryHost(registryOpts) != host {
+		return registry.TLSOptions{}
+	}
+	return cli.TLSOptions(registryOpts)
+}
+
+// registryHost returns the host of the configured registry
+func registryHost(registryOpts flags.RegistryOptions) string {
+	return strings.TrimPrefix(strings.TrimPrefix(registryOpts.Url, "http://"), "https://")
+}
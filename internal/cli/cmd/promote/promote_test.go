@@ -0,0 +1,118 @@
+package promote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/spf13/pflag"
+)
+
+func TestPromoteCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := NewPromoteCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Copy an image, including every architecture of a manifest list, from one registry to another while preserving the digests"
+	if expected != cmd.Long {
+		t.Errorf("expected %v, got %v", expected, cmd.Long)
+	}
+
+	expected = "Copy an image from one registry to another"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate the number of commands attached to this command
+	commands := cmd.Commands()
+	expectedCommands := 0
+	actualCommands := len(commands)
+	if actualCommands != expectedCommands {
+		t.Errorf("expected commands %v, got %v", expectedCommands, actualCommands)
+	}
+
+	// validate the number of flags
+	expectedFlagCount := 4
+	actualFlagCount := 0
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		actualFlagCount++
+	})
+
+	if actualFlagCount != expectedFlagCount {
+		t.Errorf("expected %v flags, got %v", expectedFlagCount, actualFlagCount)
+	}
+
+	// validate each flag
+	for _, name := range []string{"source-registry-user", "source-registry-password", "target-registry-user", "target-registry-password"} {
+		if _, err := cmd.Flags().GetString(name); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func Test_getUser(t *testing.T) {
+	// prevent the credentials of the environment from being used
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Setenv("SOURCE_PASSWORD", "secret")
+
+	registryOpts := flags.RegistryOptions{Url: "docker.io", Username: "user", Password: "password"}
+
+	user, err := getUser(flags.CredentialOptions{Username: "source", Password: "env:SOURCE_PASSWORD"}, registryOpts, "ghcr.io")
+	if err != nil || user == nil || user.Name != "source" || user.Password != "secret" {
+		t.Errorf("expected the source credentials, got %+v, %v", user, err)
+	}
+
+	user, _ = getUser(flags.CredentialOptions{}, registryOpts, "docker.io")
+	if user == nil || user.Name != "user" || user.Password != "password" {
+		t.Errorf("expected the registry credentials, got %+v", user)
+	}
+
+	// the registry credentials are never sent to another registry
+	user, _ = getUser(flags.CredentialOptions{}, registryOpts, "ghcr.io")
+	if user != nil {
+		t.Errorf("expected anonymous access, got %+v", user)
+	}
+
+	user, _ = getUser(flags.CredentialOptions{}, flags.RegistryOptions{}, "docker.io")
+	if user != nil {
+		t.Errorf("expected anonymous access, got %+v", user)
+	}
+
+	// a password on the command line is rejected
+	if _, err := getUser(flags.CredentialOptions{Username: "source", Password: "secret"}, registryOpts, "ghcr.io"); err == nil {
+		t.Error("expected an error for a raw password")
+	}
+}
+
+func Test_getUser_dockerCredentials(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+
+	// ghcr.io has the credentials of docker login, encoded as base64 of "docker:stored"
+	config := `{"auths": {"ghcr.io": {"auth": "ZG9ja2VyOnN0b3JlZA=="}}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	registryOpts := flags.RegistryOptions{Url: "docker.io", Username: "user", Password: "password"}
+
+	user, err := getUser(flags.CredentialOptions{}, registryOpts, "ghcr.io")
+	if err != nil || user == nil || user.Name != "docker" || user.Password != "stored" {
+		t.Errorf("expected the docker credentials, got %+v, %v", user, err)
+	}
+}
+
+func Test_getTLSOptions(t *testing.T) {
+	registryOpts := flags.RegistryOptions{Url: "http://localhost:5000", CaFile: "/etc/ca.pem"}
+
+	tlsOpts := getTLSOptions(registryOpts, "localhost:5000")
+	if !tlsOpts.Insecure || tlsOpts.CaFile != "/etc/ca.pem" {
+		t.Errorf("expected the registry tls options, got %+v", tlsOpts)
+	}
+
+	tlsOpts = getTLSOptions(registryOpts, "docker.io")
+	if !tlsOpts.IsDefault() {
+		t.Errorf("expected the default tls options, got %+v", tlsOpts)
+	}
+}
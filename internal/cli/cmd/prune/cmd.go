@@ -0,0 +1,147 @@
+package prune
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"tugboat/internal/cli"
+	"tugboat/internal/driver"
+	"tugboat/internal/image"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/reference"
+	"tugboat/internal/pkg/tmpl"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewPruneCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	pruneFlags := flags.NewPruneFlagGroup()
+	imageFlags := flags.NewImageFlagsGroup()
+
+	cmd := &cobra.Command{
+		Use:   "prune [IMAGE]",
+		Short: "Delete old tags of an image from the registry",
+		Long:  pruneDescription,
+		Args:  cli.RequiresMaxArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// the image flags are shared with other commands, so they are bound to the command being run
+			if err := flags.Bind(cmd, pruneFlags); err != nil {
+				return err
+			}
+			return flags.Bind(cmd, imageFlags)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags, pruneFlags, imageFlags)
+			if err != nil {
+				return err
+			}
+			return runPrune(opts, args)
+		},
+	}
+
+	flags.AddFlags(cmd, pruneFlags, imageFlags)
+
+	return cmd
+}
+
+var pruneDescription = `Delete the tags of an image from the registry according to the retention policies. Only the plan is shown unless --apply is set`
+
+func runPrune(opts *flags.Options, args []string) error {
+	log.Debugf("Prune Options: %+v", opts)
+	log.Debugf("Prune Args: %+v", args)
+
+	ctx := context.Background()
+
+	imageName := opts.Image.Name
+	if len(args) > 0 {
+		imageName = args[0]
+	}
+
+	if imageName == "" {
+		return errors.New("an image must be provided")
+	}
+
+	compiledImage, err := tmpl.CompileString(imageName, opts)
+	if err != nil {
+		return err
+	}
+
+	olderThan, err := parseAge(opts.Prune.OlderThan)
+	if err != nil {
+		return err
+	}
+
+	registry, err := cli.NewRegistry(opts.Global.Registry)
+	if err != nil {
+		return err
+	}
+
+	uri, err := driver.GenerateUri(registry.ServerAddress, registry.Namespace, compiledImage, opts.Global.Official, reference.ArchOmit)
+	if err != nil {
+		return err
+	}
+
+	apply := opts.Prune.Apply && !opts.Global.DryRun
+	if apply {
+		if err := cli.CheckRegistryAuth(ctx, registry); err != nil {
+			return err
+		}
+	}
+
+	pruneOpts := image.PruneOptions{
+		Repository:             uri.ShortName(),
+		KeepLast:               opts.Prune.KeepLast,
+		Keep:                   opts.Prune.Keep,
+		OlderThan:              olderThan,
+		SupportedArchitectures: opts.Image.SupportedArchitectures,
+		Apply:                  apply,
+	}
+
+	decisions, err := image.Prune(ctx, registry.Client(), pruneOpts)
+
+	deleted := 0
+	for _, decision := range decisions {
+		if decision.Delete {
+			deleted++
+			log.Infof("delete %s: %s", decision.Tag, decision.Reason)
+		} else {
+			log.Infof("keep   %s: %s", decision.Tag, decision.Reason)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !apply {
+		log.Infof("%d of %d tags would be deleted, run with --apply to delete them", deleted, len(decisions))
+		return nil
+	}
+
+	log.Infof("Deleted %d of %d tags", deleted, len(decisions))
+	return nil
+}
+
+// parseAge parses a duration that also accepts a number of days (i.e. 30d)
+func parseAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, errors.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, errors.Errorf("invalid age %q", value)
+	}
+	return age, nil
+}
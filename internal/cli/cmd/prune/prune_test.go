@@ -0,0 +1,144 @@
+package prune
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"tugboat/internal/cli/cmd/tag"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/registry/registrytest"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestPruneCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := NewPruneCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Delete the tags of an image from the registry according to the retention policies. Only the plan is shown unless --apply is set"
+	if expected != cmd.Long {
+		t.Errorf("expected %v, got %v", expected, cmd.Long)
+	}
+
+	expected = "Delete old tags of an image from the registry"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate the number of commands attached to this command
+	commands := cmd.Commands()
+	expectedCommands := 0
+	actualCommands := len(commands)
+	if actualCommands != expectedCommands {
+		t.Errorf("expected commands %v, got %v", expectedCommands, actualCommands)
+	}
+
+	// validate what flags are attached to this command
+	if ok := cmd.HasLocalFlags(); !ok {
+		t.Error("expected to see flags, but there are none")
+	}
+
+	// validate the number of flags
+	expectedFlagCount := 5
+	actualFlagCount := 0
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		actualFlagCount++
+	})
+
+	if actualFlagCount != expectedFlagCount {
+		t.Errorf("expected %v flags, got %v", expectedFlagCount, actualFlagCount)
+	}
+
+	// validate each flag
+	if _, err := cmd.Flags().GetInt("keep-last"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringArray("keep"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetString("older-than"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetBool("apply"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetStringSlice("architectures"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "", expected: 0},
+		{value: "72h", expected: 72 * time.Hour},
+		{value: "30d", expected: 30 * 24 * time.Hour},
+		{value: "1h30m", expected: 90 * time.Minute},
+		{value: "d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			actual, err := parseAge(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPruneCommand_architectures(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	fake := registrytest.New(t, "basic", &registrytest.User{Name: "user", Password: "password"})
+	for _, tag := range []string{"amd64-1.0.0", "amd64-1.1.0"} {
+		// a manifest for each tag, so the tags are different images
+		content := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"annotations":{"tag":%q}}`, registrytest.MediaTypeDockerManifest, tag)
+		fake.PutManifest("ns/app", tag, registrytest.MediaTypeDockerManifest, []byte(content))
+	}
+	viper.Set("registry.url", fake.Host())
+	viper.Set("registry.namespace", "ns")
+	viper.Set("registry.user", "user")
+	viper.Set("registry.password", "password")
+	viper.Set("registry.insecure", true)
+
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := NewPruneCommand(globalFlags)
+	// the tag command binds the architectures after the prune command
+	tag.NewTagCommand(globalFlags)
+
+	cmd.SetArgs([]string{"app", "--keep-last", "1", "-a", "amd64"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the architecture tags are only versions when the architectures reach the plan
+	if !strings.Contains(out.String(), "delete amd64-1.0.0") {
+		t.Errorf("expected amd64-1.0.0 to be deleted, got %v", out.String())
+	}
+	if !strings.Contains(out.String(), "keep   amd64-1.1.0") {
+		t.Errorf("expected amd64-1.1.0 to be kept, got %v", out.String())
+	}
+}
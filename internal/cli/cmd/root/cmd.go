@@ -1,7 +1,10 @@
 package root
 
 import (
+	"context"
 	"os"
+	"strings"
+	"tugboat/internal/cli"
 	"tugboat/internal/config"
 	"tugboat/internal/pkg/flags"
 	"tugboat/internal/pkg/logging"
@@ -25,10 +28,38 @@ func NewRootCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 				return err
 			}
 
-			globalOptions := globalFlags.ToOptions()
+			// report the typos and invalid values of the config files before they are used, the
+			// values are checked as they are written before their variables are expanded
+			if configFile := config.ConfigFileUsed(); configFile != "" && !skipsConfigValidation(cmd) {
+				if err := config.Validate(configFile); err != nil {
+					return err
+				}
+			}
+
+			// the profiles are merged over the config before the options are read
+			profiles, err := config.ApplyProfiles(viper.GetStringSlice(flags.ProfileFlag.ConfigName))
+			if err != nil {
+				return err
+			}
+
+			// the variables of the config values are expanded once the profiles are merged
+			if err := config.Interpolate(context.TODO()); err != nil {
+				return err
+			}
+
+			globalOptions, err := globalFlags.ToOptions()
+			if err != nil {
+				return err
+			}
 
 			logging.Initialize(os.Stderr, globalOptions.Debug)
 
+			flags.WarnDeprecatedEnv()
+
+			if len(profiles) > 0 {
+				log.Infof("Using the profiles: %s", strings.Join(profiles, ", "))
+			}
+
 			if globalOptions.DryRun {
 				log.Warn("Dry run in progress, nothing will be executed")
 			}
@@ -46,4 +77,14 @@ func NewRootCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 	return cmd
 }
 
+// skipsConfigValidation returns true when the command or one of its parents validates the config file itself
+func skipsConfigValidation(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[cli.SkipConfigValidation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 var rootDescription = `A tool to build and publish multi-architecture container images`
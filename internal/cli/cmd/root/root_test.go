@@ -36,7 +36,7 @@ func TestRootCommand(t *testing.T) {
 	}
 
 	// validate the number of flags
-	expectedFlagCount := 9
+	expectedFlagCount := 17
 	actualFlagCount := 0
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		actualFlagCount++
@@ -51,6 +51,10 @@ func TestRootCommand(t *testing.T) {
 		t.Error(err)
 	}
 
+	if _, err := cmd.Flags().GetStringSlice("profile"); err != nil {
+		t.Error(err)
+	}
+
 	if _, err := cmd.Flags().GetBool("dry-run"); err != nil {
 		t.Error(err)
 	}
@@ -75,6 +79,28 @@ func TestRootCommand(t *testing.T) {
 		t.Error(err)
 	}
 
+	if _, err := cmd.Flags().GetString("registry-password-file"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetBool("registry-password-stdin"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := cmd.Flags().GetBool("registry-insecure"); err != nil {
+		t.Error(err)
+	}
+
+	for _, name := range []string{"registry-ca-file", "registry-client-cert", "registry-client-key"} {
+		if _, err := cmd.Flags().GetString(name); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := cmd.Flags().GetDuration("registry-rate-limit-wait"); err != nil {
+		t.Error(err)
+	}
+
 	if _, err := cmd.Flags().GetBool("official"); err != nil {
 		t.Error(err)
 	}
@@ -19,13 +19,25 @@ func NewTagCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 	imageFlags := flags.NewImageFlagsGroup()
 
 	cmd := &cobra.Command{
-		Use:   "tag SOURCE_IMAGE",
+		Use:   "tag SOURCE_IMAGE [IMAGE...]",
 		Short: "Create a tag that refers to another image",
 		Long:  tagDescription,
-		Args:  cli.ExactArgs(1),
+		Args:  cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := flags.ToOptions(globalFlags, tagFlags, imageFlags)
-			return runTag(opts, args)
+			imagesOpts, err := flags.ToImageOptions(globalFlags, args[1:], tagFlags, imageFlags)
+			if err != nil {
+				return err
+			}
+
+			for _, opts := range imagesOpts {
+				if len(imagesOpts) > 1 {
+					log.Infof("Tagging %s", opts.Image.Name)
+				}
+				if err := runTag(opts, args[:1]); err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	}
 
@@ -57,16 +69,51 @@ func runTag(opts *flags.Options, args []string) error {
 	log.Debugf("compiledSourceImage: %s", compiledSourceImage)
 	log.Debugf("compiledTags: %s", compiledTags)
 
-	registry, err := registry.NewRegistry(
-		opts.Global.Registry.Url,
-		opts.Global.Registry.Namespace,
-		opts.Global.Registry.Username,
-		opts.Global.Registry.Password,
-	)
+	registry, err := cli.NewRegistry(opts.Global.Registry)
 	if err != nil {
 		return err
 	}
 
+	// the additional registries are only published to when pushing
+	isPushing := opts.Tag.Push || opts.Tag.Remote
+	var mirrorRegistries []flags.RegistryOptions
+	if isPushing {
+		mirrorRegistries = opts.Global.Registries
+	}
+
+	mirrors, err := cli.NewRegistries(mirrorRegistries)
+	if err != nil {
+		return err
+	}
+
+	if isPushing && !opts.Global.DryRun {
+		if err := cli.CheckRegistryAuth(ctx, registry); err != nil {
+			return err
+		}
+
+		if err := cli.CheckRegistryAuth(ctx, mirrors...); err != nil {
+			return err
+		}
+	}
+
+	if opts.Tag.Remote {
+		remoteTagOptions := image.RemoteTagOptions{
+			SourceImage:            compiledSourceImage,
+			Tags:                   compiledTags,
+			SupportedArchitectures: opts.Image.SupportedArchitectures,
+			Registry:               registry,
+			Official:               opts.Global.Official,
+			ArchitectureTag:        flags.DefaultArchOption,
+			DryRun:                 opts.Global.DryRun,
+		}
+
+		if err := image.RemoteTag(ctx, registry.Client(), remoteTagOptions); err != nil {
+			return err
+		}
+
+		return mirror(ctx, opts, registry, mirrors, compiledSourceImage, compiledTags)
+	}
+
 	driverOpts := driver.DriverOptions{
 		Registry:        registry,
 		DryRun:          opts.Global.DryRun,
@@ -88,5 +135,26 @@ func runTag(opts *flags.Options, args []string) error {
 	if err := image.Tag(ctx, d, tagOptions); err != nil {
 		return err
 	}
+
+	if opts.Tag.Push {
+		return mirror(ctx, opts, registry, mirrors, compiledSourceImage, compiledTags)
+	}
 	return nil
 }
+
+// mirror copies the new tags to the additional registries
+func mirror(ctx context.Context, opts *flags.Options, source *registry.Registry, mirrors []*registry.Registry, sourceImage string, tags []string) error {
+	if len(mirrors) == 0 {
+		return nil
+	}
+
+	mirrorOpts := image.MirrorOptions{
+		Images:                 image.TaggedImages(sourceImage, tags),
+		SupportedArchitectures: opts.Image.SupportedArchitectures,
+		IncludeManifestLists:   opts.Tag.Remote,
+		Official:               opts.Global.Official,
+		ArchitectureTag:        flags.DefaultArchOption,
+		DryRun:                 opts.Global.DryRun,
+	}
+	return image.Mirror(ctx, source, mirrors, mirrorOpts)
+}
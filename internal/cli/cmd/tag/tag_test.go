@@ -36,7 +36,7 @@ func TestTagCommand(t *testing.T) {
 	}
 
 	// validate the number of flags
-	expectedFlagCount := 3
+	expectedFlagCount := 4
 	actualFlagCount := 0
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		actualFlagCount++
@@ -58,4 +58,8 @@ func TestTagCommand(t *testing.T) {
 	if _, err := cmd.Flags().GetBool("push"); err != nil {
 		t.Error(err)
 	}
+
+	if _, err := cmd.Flags().GetBool("remote"); err != nil {
+		t.Error(err)
+	}
 }
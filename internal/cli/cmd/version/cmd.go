@@ -18,7 +18,10 @@ func NewVersionCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 		Args:  cli.NoArgs,
 		Long:  versionDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := flags.ToOptions(globalFlags, versionFlags)
+			opts, err := flags.ToOptions(globalFlags, versionFlags)
+			if err != nil {
+				return err
+			}
 			return runVersion(opts)
 		},
 	}
@@ -26,6 +29,10 @@ func NewVersionCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
 	flags.AddFlags(cmd, versionFlags)
 	flags.Bind(cmd, versionFlags)
 
+	cmd.AddCommand(
+		newNextCommand(globalFlags),
+	)
+
 	return cmd
 }
 
@@ -0,0 +1,63 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"tugboat/internal/cli"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/git"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newNextCommand(globalFlags *flags.GlobalFlagGroup) *cobra.Command {
+	nextFlags := flags.NewVersionNextFlagGroup()
+
+	cmd := &cobra.Command{
+		Use:       "next [STRATEGY]",
+		Short:     "Show the image version derived from the git history",
+		Long:      nextDescription,
+		Args:      cli.RequiresMaxArgs(1),
+		ValidArgs: git.VersionStrategies,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := flags.ToOptions(globalFlags, nextFlags)
+			if err != nil {
+				return err
+			}
+			return runNext(opts, args, os.Stdout)
+		},
+	}
+
+	return cmd
+}
+
+var nextDescription = `Show the image version the image.version-strategy derives from the git history, which is the
+{{.Version}} of the tags when no image.version is defined. Provide a strategy to preview another one:
+` + strings.Join(git.VersionStrategies, ", ")
+
+func runNext(opts *flags.Options, args []string, out io.Writer) error {
+	log.Debugf("Version Next Options: %+v", opts)
+	log.Debugf("Version Next Args: %+v", args)
+
+	strategy := opts.Version.Next.Strategy
+	if len(args) > 0 {
+		strategy = args[0]
+	}
+
+	if strategy == "" {
+		return errors.Errorf("no version strategy is defined, set image.version-strategy or provide one of %s", strings.Join(git.VersionStrategies, ", "))
+	}
+
+	version, err := git.Version(context.TODO(), strategy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, version)
+	return nil
+}
@@ -1,8 +1,12 @@
 package version
 
 import (
+	"bytes"
+	"strconv"
+	"strings"
 	"testing"
 	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/git"
 
 	"github.com/spf13/pflag"
 )
@@ -24,7 +28,7 @@ func TestVersionCommand(t *testing.T) {
 
 	// validate the number of commands attached to this command
 	commands := cmd.Commands()
-	expectedCommands := 0
+	expectedCommands := 1
 	actualCommands := len(commands)
 	if actualCommands != expectedCommands {
 		t.Errorf("expected commands %v, got %v", expectedCommands, actualCommands)
@@ -52,3 +56,43 @@ func TestVersionCommand(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func Test_newNextCommand(t *testing.T) {
+	globalFlags := flags.NewGlobalFlagGroup()
+	cmd := newNextCommand(globalFlags)
+
+	// validate the description strings
+	expected := "Show the image version derived from the git history"
+	if expected != cmd.Short {
+		t.Errorf("expected %v, got %v", expected, cmd.Short)
+	}
+
+	// validate what flags are attached to this command
+	if ok := cmd.HasLocalFlags(); ok {
+		t.Error("expected no flags, but there are flags")
+	}
+}
+
+func Test_runNext(t *testing.T) {
+	var out bytes.Buffer
+	opts := &flags.Options{Version: flags.VersionOptions{Next: flags.VersionNextOptions{Strategy: git.CommitCount}}}
+	if err := runNext(opts, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strconv.Atoi(strings.TrimSpace(out.String())); err != nil {
+		t.Errorf("expected the number of commits, got %q", out.String())
+	}
+
+	// the strategy of the argument is previewed instead of the configured one
+	out.Reset()
+	if err := runNext(opts, []string{git.GitDescribe}, &out); err != nil || out.Len() == 0 {
+		t.Errorf("expected a version, got %q, %v", out.String(), err)
+	}
+
+	if err := runNext(&flags.Options{}, nil, &out); err == nil {
+		t.Error("expected an error when no strategy is defined")
+	}
+	if err := runNext(opts, []string{"latest"}, &out); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
@@ -2,7 +2,12 @@ package commands
 
 import (
 	"tugboat/internal/cli/cmd/build"
+	"tugboat/internal/cli/cmd/config"
+	"tugboat/internal/cli/cmd/login"
+	"tugboat/internal/cli/cmd/logout"
 	"tugboat/internal/cli/cmd/manifest"
+	"tugboat/internal/cli/cmd/promote"
+	"tugboat/internal/cli/cmd/prune"
 	"tugboat/internal/cli/cmd/root"
 	"tugboat/internal/cli/cmd/tag"
 	"tugboat/internal/cli/cmd/version"
@@ -27,9 +32,24 @@ func addCommands(cmd *cobra.Command, globalFlags *flags.GlobalFlagGroup) {
 		// build
 		build.NewBuildCommand(globalFlags),
 
+		// config
+		config.NewConfigCommand(globalFlags),
+
+		// login
+		login.NewLoginCommand(globalFlags),
+
+		// logout
+		logout.NewLogoutCommand(globalFlags),
+
 		// manifest
 		manifest.NewManifestCommand(globalFlags),
 
+		// promote
+		promote.NewPromoteCommand(globalFlags),
+
+		// prune
+		prune.NewPruneCommand(globalFlags),
+
 		// tag
 		tag.NewTagCommand(globalFlags),
 
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/reference"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// stdin is read at most once, only one registry can set password-stdin (see the global options)
+var stdin = struct {
+	once     sync.Once
+	reader   io.Reader
+	password string
+	err      error
+}{reader: os.Stdin}
+
+// NewRegistry creates the registry defined in the options after resolving its credentials
+func NewRegistry(opts flags.RegistryOptions) (*registry.Registry, error) {
+	provider := CredentialProvider(opts)
+	if provider != nil {
+		if opts.Username != "" || opts.Password != "" || opts.PasswordFile != "" || opts.PasswordStdin {
+			return nil, errors.New("the registry credentials cannot be used with a credential provider")
+		}
+
+		// the first credentials validate the provider before any work is done
+		host := strings.TrimPrefix(strings.TrimPrefix(opts.Url, "https://"), "http://")
+		user, _, err := provider.Credentials(context.Background(), host)
+		if err != nil {
+			return nil, err
+		}
+		opts.Username = user.Name
+		opts.Password = user.Password
+	}
+
+	resolved, err := ResolveRegistryCredentials(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := registry.NewRegistry(resolved.Url, resolved.Namespace, resolved.Username, resolved.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	r.CredentialProvider = provider
+	r.TLS = TLSOptions(opts)
+	r.RateLimitWait = opts.RateLimitWait
+
+	// report invalid certificates before any work is done
+	if _, err := r.TLS.Config(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSOptions returns the options securing the connection to the registry
+func TLSOptions(opts flags.RegistryOptions) registry.TLSOptions {
+	return registry.TLSOptions{
+		Insecure:   opts.Insecure || reference.IsInsecure(opts.Url),
+		CaFile:     opts.CaFile,
+		ClientCert: opts.ClientCert,
+		ClientKey:  opts.ClientKey,
+	}
+}
+
+// CredentialProvider returns the credential provider of the registry, nil when none is configured
+func CredentialProvider(opts flags.RegistryOptions) *registry.CredentialProvider {
+	if opts.CredentialProvider.Command == "" {
+		return nil
+	}
+
+	return &registry.CredentialProvider{
+		Command: opts.CredentialProvider.Command,
+		Args:    opts.CredentialProvider.Args,
+		Env:     opts.CredentialProvider.Env,
+	}
+}
+
+// NewRegistries creates the additional registries images are published to
+func NewRegistries(registries []flags.RegistryOptions) ([]*registry.Registry, error) {
+	var result []*registry.Registry
+	for _, opts := range registries {
+		r, err := NewRegistry(opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "registry %s", opts.Url)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// CheckRegistryAuth validates the credentials of each registry before a command does any work, so
+// invalid credentials are reported before images are built or pulled instead of when pushing
+func CheckRegistryAuth(ctx context.Context, registries ...*registry.Registry) error {
+	for _, r := range registries {
+		log.Debugf("Checking the credentials for %v", r.ServerAddress)
+
+		if err := r.Client().Ping(ctx); err != nil {
+			return errors.Wrapf(err, "checking the credentials for %v", r.ServerAddress)
+		}
+	}
+	return nil
+}
+
+// ResolveRegistryCredentials returns the registry options with the username and password read from
+// the locations they refer to (env:VAR, file:/path, the password file or stdin)
+func ResolveRegistryCredentials(opts flags.RegistryOptions) (flags.RegistryOptions, error) {
+	sources := 0
+	for _, isSet := range []bool{opts.Password != "", opts.PasswordFile != "", opts.PasswordStdin} {
+		if isSet {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return opts, errors.New("only one of the registry password, password file, or password stdin options can be used")
+	}
+
+	username, err := registry.ResolveSecret(opts.Username)
+	if err != nil {
+		return opts, errors.Wrap(err, "resolving the registry username")
+	}
+	opts.Username = username
+
+	switch {
+	case opts.PasswordFile != "":
+		opts.Password, err = registry.ReadSecretFile(opts.PasswordFile)
+	case opts.PasswordStdin:
+		opts.Password, err = readStdinPassword()
+	default:
+		opts.Password, err = registry.ResolveSecret(opts.Password)
+	}
+	if err != nil {
+		return opts, errors.Wrap(err, "resolving the registry password")
+	}
+
+	opts.PasswordFile = ""
+	opts.PasswordStdin = false
+
+	return opts, nil
+}
+
+func readStdinPassword() (string, error) {
+	stdin.once.Do(func() {
+		stdin.password, stdin.err = registry.ReadSecret(stdin.reader)
+		if stdin.err == nil && stdin.password == "" {
+			stdin.err = errors.New("no password was provided through stdin")
+		}
+	})
+	return stdin.password, stdin.err
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/flags"
+)
+
+func TestResolveRegistryCredentials(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("file-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TUGBOAT_TEST_USER", "env-user")
+
+	stdin.reader = strings.NewReader("stdin-password\n")
+
+	testCases := []struct {
+		name             string
+		opts             flags.RegistryOptions
+		expectedUser     string
+		expectedPassword string
+		expectedErr      bool
+	}{
+		{
+			name:             "plain credentials",
+			opts:             flags.RegistryOptions{Username: "user", Password: "password"},
+			expectedUser:     "user",
+			expectedPassword: "password",
+		},
+		{
+			name:             "env and file indirection",
+			opts:             flags.RegistryOptions{Username: "env:TUGBOAT_TEST_USER", Password: "file:" + passwordFile},
+			expectedUser:     "env-user",
+			expectedPassword: "file-password",
+		},
+		{
+			name:             "password file",
+			opts:             flags.RegistryOptions{Username: "user", PasswordFile: passwordFile},
+			expectedUser:     "user",
+			expectedPassword: "file-password",
+		},
+		{
+			name:             "password stdin",
+			opts:             flags.RegistryOptions{Username: "user", PasswordStdin: true},
+			expectedUser:     "user",
+			expectedPassword: "stdin-password",
+		},
+		{
+			name:        "multiple password sources",
+			opts:        flags.RegistryOptions{Username: "user", Password: "password", PasswordStdin: true},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := ResolveRegistryCredentials(tc.opts)
+
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error, but there was none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("An unexpected error occurred: %v", err)
+			}
+
+			if resolved.Username != tc.expectedUser || resolved.Password != tc.expectedPassword {
+				t.Errorf("expected %v:%v, got %v:%v", tc.expectedUser, tc.expectedPassword, resolved.Username, resolved.Password)
+			}
+		})
+	}
+}
+
+func TestNewRegistry_credentialProvider(t *testing.T) {
+	provider := filepath.Join(t.TempDir(), "provider")
+	script := "#!/bin/sh\necho '{\"username\": \"AWS\", \"password\": \"token\"}'\n"
+	if err := os.WriteFile(provider, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := flags.RegistryOptions{
+		Url:                "https://123456789012.dkr.ecr.us-east-1.amazonaws.com",
+		CredentialProvider: flags.CredentialProviderOptions{Command: provider},
+	}
+
+	r, err := NewRegistry(opts)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+
+	if r.CredentialProvider == nil {
+		t.Error("expected the registry to use the credential provider")
+	}
+
+	if r.User.Name != "AWS" || r.User.Password != "token" {
+		t.Errorf("expected AWS:token, got %v:%v", r.User.Name, r.User.Password)
+	}
+
+	// the provider replaces the other credentials
+	opts.Password = "password"
+	if _, err := NewRegistry(opts); err == nil {
+		t.Error("expected an error, but there was none")
+	}
+}
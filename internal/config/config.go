@@ -8,7 +8,13 @@ import (
 	"github.com/spf13/viper"
 )
 
+// configFileUsed is the path of the last configuration file read by LoadConfig
+var configFileUsed string
+
 func LoadConfig(configFile string) error {
+	configFileUsed = ""
+	fileKeys = make(map[string]string)
+
 	validFileNames := []string{"tugboat", ".tugboat"}
 
 	for _, file := range validFileNames {
@@ -35,6 +41,17 @@ func LoadConfig(configFile string) error {
 				// Config file was found but another error was produced
 				return errors.Errorf("loading the config file failed: %v", err)
 			}
+		} else {
+			configFileUsed = viper.ConfigFileUsed()
+		}
+	}
+
+	if configFileUsed != "" {
+		if err := migrateConfig(viper.GetViper(), configFileUsed); err != nil {
+			return err
+		}
+		if err := applyExtends(configFileUsed); err != nil {
+			return err
 		}
 	}
 
@@ -42,3 +59,9 @@ func LoadConfig(configFile string) error {
 
 	return nil
 }
+
+// ConfigFileUsed returns the path of the configuration file that was loaded, an empty string is
+// returned when no configuration file was found
+func ConfigFileUsed() string {
+	return configFileUsed
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// extendsKey lists the configuration files the configuration is based on, i.e. the shared defaults
+// of an organization checked out as a submodule
+const extendsKey = "extends"
+
+// fileKeys are the keys set by the configuration file and the files it extends, and the file that
+// set them last
+var fileKeys = make(map[string]string)
+
+// applyExtends merges the files extended by the configuration file under its values, the files are
+// merged in order so a later file overrides an earlier one and the configuration file overrides them all
+func applyExtends(configFile string) error {
+	if !viper.InConfig(extendsKey) {
+		return nil
+	}
+
+	merged, err := readExtended(configFile, nil)
+	if err != nil {
+		return err
+	}
+
+	return viper.MergeConfigMap(merged)
+}
+
+// readExtended reads a configuration file merged over the files it extends, the chain holds the
+// files being read to detect a file extending itself
+func readExtended(file string, chain []string) (map[string]interface{}, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving %s", file)
+	}
+
+	for _, parent := range chain {
+		if parent == path {
+			return nil, errors.Errorf("the config file extends itself: %s", strings.Join(append(chain, path), " -> "))
+		}
+	}
+	chain = append(chain, path)
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "loading the config file %s", file)
+	}
+	if err := migrateConfig(v, path); err != nil {
+		return nil, err
+	}
+
+	extends, err := extendsOf(v.Get(extendsKey))
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	merged := viper.New()
+	for _, extended := range extends {
+		// the paths are relative to the file extending them
+		if !filepath.IsAbs(extended) {
+			extended = filepath.Join(filepath.Dir(path), extended)
+		}
+
+		log.Debugf("%s extends %s", path, extended)
+		settings, err := readExtended(extended, chain)
+		if err != nil {
+			return nil, err
+		}
+		if err := merged.MergeConfigMap(settings); err != nil {
+			return nil, errors.Wrapf(err, "merging %s", extended)
+		}
+	}
+
+	if err := merged.MergeConfigMap(v.AllSettings()); err != nil {
+		return nil, errors.Wrapf(err, "merging %s", file)
+	}
+	recordKeys(fileKeys, file, "", v.AllSettings())
+
+	return merged.AllSettings(), nil
+}
+
+// extendsOf returns the paths of the extends key, which is a path or a list of paths
+func extendsOf(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		var paths []string
+		for _, path := range strings.Split(v, ",") {
+			paths = append(paths, strings.TrimSpace(path))
+		}
+		return paths, nil
+	case []interface{}:
+		var paths []string
+		for _, item := range v {
+			path, ok := item.(string)
+			if !ok {
+				return nil, errors.Errorf("%s expects a list of paths, got %v", extendsKey, item)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	}
+
+	return nil, errors.Errorf("%s expects a path or a list of paths, got %v", extendsKey, value)
+}
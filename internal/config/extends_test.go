@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Cleanup(func() {
+		viper.Reset()
+		configFileUsed = ""
+		fileKeys = make(map[string]string)
+	})
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadConfig_extends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"defaults/base.yaml": `
+registry:
+  url: docker.io
+  namespace: acme
+build:
+  labels:
+    - com.acme.team=platform
+`,
+		"defaults/arm.yaml": `
+extends: base.yaml
+image:
+  supported-architectures: [arm64]
+`,
+		"defaults/multi-arch.yaml": `
+image:
+  supported-architectures: [amd64, arm64]
+`,
+		"tugboat.yaml": `
+extends:
+  - defaults/arm.yaml
+  - defaults/multi-arch.yaml
+registry:
+  namespace: app-team
+image:
+  name: app
+`,
+	})
+
+	if err := LoadConfig(filepath.Join(dir, "tugboat.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"registry.url":                  "docker.io",
+		"registry.namespace":            "app-team",
+		"image.name":                    "app",
+		"image.supported-architectures": []string{"amd64", "arm64"},
+		"build.labels":                  []string{"com.acme.team=platform"},
+	}
+	for key, value := range expected {
+		var actual interface{} = viper.GetString(key)
+		if _, ok := value.([]string); ok {
+			actual = viper.GetStringSlice(key)
+		}
+		if !reflect.DeepEqual(actual, value) {
+			t.Errorf("expected %v to be %v, got %v", key, value, actual)
+		}
+	}
+}
+
+func TestLoadConfig_extendsCycle(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.yaml":       "extends: b.yaml\n",
+		"b.yaml":       "extends: a.yaml\n",
+		"tugboat.yaml": "extends: a.yaml\n",
+	})
+
+	err := LoadConfig(filepath.Join(dir, "tugboat.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "extends itself") {
+		t.Errorf("expected a cycle to be reported, got %v", err)
+	}
+}
+
+func TestLoadConfig_extendsMissing(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"tugboat.yaml": "extends: defaults/tugboat.yaml\n",
+	})
+
+	if err := LoadConfig(filepath.Join(dir, "tugboat.yaml")); err == nil {
+		t.Error("expected an error for a missing extended file")
+	}
+}
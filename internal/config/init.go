@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"tugboat/internal/pkg/git"
+
+	"github.com/pkg/errors"
+)
+
+// maxDockerfileDepth is how deep in the directory tree Dockerfiles are looked for
+const maxDockerfileDepth = 3
+
+// skippedDirs are not searched for Dockerfiles
+var skippedDirs = []string{".git", "node_modules", "vendor"}
+
+// Project is what is found in a directory to suggest the values of a new configuration file
+type Project struct {
+	// Dockerfiles are relative to the directory, the one at the root comes first
+	Dockerfiles []string
+	// Namespace and ImageName are suggested from the git remote (i.e. github.com/<namespace>/<image>)
+	Namespace string
+	ImageName string
+	// BaseImages are the base images of the final stage of each Dockerfile
+	BaseImages []string
+	// Platforms are set with --platform in the FROM instructions (i.e. linux/arm64)
+	Platforms []string
+}
+
+// InitValues are the values of a new configuration file
+type InitValues struct {
+	Registry      string
+	Namespace     string
+	ImageName     string
+	Dockerfile    string
+	Context       string
+	Architectures []string
+}
+
+// InspectProject finds the Dockerfiles of the directory and suggests an image from the git remote
+func InspectProject(dir string, remote string) (*Project, error) {
+	project := &Project{}
+	project.Namespace, project.ImageName = remoteImage(remote)
+
+	dockerfiles, err := findDockerfiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	project.Dockerfiles = dockerfiles
+
+	for _, dockerfile := range dockerfiles {
+		content, err := os.ReadFile(filepath.Join(dir, dockerfile))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", dockerfile)
+		}
+
+		base, platforms := parseDockerfile(content)
+		if base != "" && !contains(project.BaseImages, base) {
+			project.BaseImages = append(project.BaseImages, base)
+		}
+		for _, platform := range platforms {
+			if !contains(project.Platforms, platform) {
+				project.Platforms = append(project.Platforms, platform)
+			}
+		}
+	}
+
+	return project, nil
+}
+
+// findDockerfiles returns the Dockerfiles in the directory tree (i.e. Dockerfile, Containerfile,
+// app.Dockerfile, Dockerfile.dev), the ones closer to the root come first
+func findDockerfiles(dir string) ([]string, error) {
+	var dockerfiles []string
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if relative != "." && (contains(skippedDirs, entry.Name()) || strings.Count(relative, string(filepath.Separator)) >= maxDockerfileDepth-1) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isDockerfile(entry.Name()) {
+			dockerfiles = append(dockerfiles, filepath.ToSlash(relative))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "looking for Dockerfiles")
+	}
+
+	sort.SliceStable(dockerfiles, func(i, j int) bool {
+		return strings.Count(dockerfiles[i], "/") < strings.Count(dockerfiles[j], "/")
+	})
+
+	return dockerfiles, nil
+}
+
+func isDockerfile(name string) bool {
+	return name == "Dockerfile" || name == "Containerfile" ||
+		strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+// fromInstruction matches FROM [--platform=<platform>] <image> [AS <name>]
+var fromInstruction = regexp.MustCompile(`(?i)^FROM\s+(?:--platform=(\S+)\s+)?(\S+)(?:\s+AS\s+(\S+))?`)
+
+// parseDockerfile returns the base image of the final stage and the platforms set in the FROM
+// instructions. Stages based on other stages are followed to the image they start from, no
+// image is returned for scratch or images set with build arguments.
+func parseDockerfile(content []byte) (string, []string) {
+	stages := make(map[string]string)
+	var base string
+	var platforms []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		matches := fromInstruction.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if matches == nil {
+			continue
+		}
+
+		if platform := matches[1]; platform != "" && !strings.Contains(platform, "$") && !contains(platforms, platform) {
+			platforms = append(platforms, platform)
+		}
+
+		image := matches[2]
+		if stage, ok := stages[strings.ToLower(image)]; ok {
+			image = stage
+		}
+		if name := matches[3]; name != "" {
+			stages[strings.ToLower(name)] = image
+		}
+		base = image
+	}
+
+	if base == "scratch" || strings.Contains(base, "$") {
+		return "", platforms
+	}
+	return base, platforms
+}
+
+// remoteImage suggests a namespace and image name from the git remote (i.e.
+// git@github.com:owner/repo.git -> owner, repo)
+func remoteImage(remote string) (string, string) {
+	source := git.SourceUrl(remote)
+	if source == "" {
+		return "", ""
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", ""
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "", ""
+	}
+
+	return imageName(parts[len(parts)-2]), imageName(path.Base(u.Path))
+}
+
+// imageName converts a name to the characters allowed in an image name
+func imageName(name string) string {
+	name = strings.ToLower(name)
+	name = regexp.MustCompile(`[^a-z0-9._-]+`).ReplaceAllString(name, "-")
+	return strings.Trim(name, "-._")
+}
+
+// RenderConfig writes a commented configuration file with the values
+func RenderConfig(values InitValues) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := initTemplate.Execute(&buffer, values); err != nil {
+		return nil, errors.Wrap(err, "rendering the config file")
+	}
+	return buffer.Bytes(), nil
+}
+
+func contains(slice []string, value string) bool {
+	for _, element := range slice {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
+
+var initTemplate = template.Must(template.New("tugboat.yaml").Funcs(template.FuncMap{
+	"configVersion": func() int { return ConfigVersion },
+}).Parse(`# tugboat.yaml created by 'tugboat config init', see example.tugboat.yaml for every option
+version: {{ configVersion }}
+
+registry:
+  url: {{ .Registry }}
+  namespace: {{ or .Namespace "<namespace>" }} # DockerHub username if using DockerHub, any if using private registry
+  # the credentials from 'docker login' are used when user and password are omitted
+  # user: <username>
+  # password: env:REGISTRY_PASSWORD
+
+image:
+  name: {{ or .ImageName "<image>" }}
+  version: ${git:describe} # or $VERSION, ${file:VERSION}
+  supported-architectures:
+{{- range .Architectures }}
+    - {{ . }}
+{{- end }}
+
+build:
+  context: "{{ .Context }}"
+  file: {{ .Dockerfile }}
+  push: false # set to true, or use --push, to publish the images
+  tags:
+    - '{{"{{"}}.ImageName{{"}}"}}:{{"{{"}}.Version{{"}}"}}'
+    - '{{"{{"}}.ImageName{{"}}"}}:latest'
+
+manifest:
+  create:
+    for: latest,{{"{{"}}.Version{{"}}"}}
+    push: true
+`))
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"tugboat/internal/pkg/flags"
+)
+
+func Test_parseDockerfile(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           string
+		expectedBase      string
+		expectedPlatforms []string
+	}{
+		{
+			name:         "single stage",
+			content:      "FROM alpine:3.19\nRUN apk add curl\n",
+			expectedBase: "alpine:3.19",
+		},
+		{
+			name:         "final stage based on a stage",
+			content:      "FROM golang:1.21 AS build\nRUN go build\nFROM gcr.io/distroless/static AS base\nfrom base as final\nCOPY --from=build /app /app\n",
+			expectedBase: "gcr.io/distroless/static",
+		},
+		{
+			name:              "platform",
+			content:           "FROM --platform=$BUILDPLATFORM golang:1.21 AS build\nFROM --platform=linux/arm64 debian:bookworm\n",
+			expectedBase:      "debian:bookworm",
+			expectedPlatforms: []string{"linux/arm64"},
+		},
+		{
+			name:    "scratch",
+			content: "FROM golang:1.21 AS build\nFROM scratch\n",
+		},
+		{
+			name:    "build argument",
+			content: "ARG BASE=alpine\nFROM $BASE\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base, platforms := parseDockerfile([]byte(tc.content))
+			if base != tc.expectedBase {
+				t.Errorf("expected base %q, got %q", tc.expectedBase, base)
+			}
+			if !reflect.DeepEqual(platforms, tc.expectedPlatforms) {
+				t.Errorf("expected platforms %v, got %v", tc.expectedPlatforms, platforms)
+			}
+		})
+	}
+}
+
+func Test_remoteImage(t *testing.T) {
+	testCases := []struct {
+		remote            string
+		expectedNamespace string
+		expectedName      string
+	}{
+		{remote: "git@github.com:gotugboat/tugboat.git", expectedNamespace: "gotugboat", expectedName: "tugboat"},
+		{remote: "https://github.com/Owner/My_App.git", expectedNamespace: "owner", expectedName: "my_app"},
+		{remote: "https://gitlab.com/group/subgroup/app", expectedNamespace: "subgroup", expectedName: "app"},
+		{remote: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.remote, func(t *testing.T) {
+			namespace, name := remoteImage(tc.remote)
+			if namespace != tc.expectedNamespace || name != tc.expectedName {
+				t.Errorf("expected %v/%v, got %v/%v", tc.expectedNamespace, tc.expectedName, namespace, name)
+			}
+		})
+	}
+}
+
+func TestInspectProject(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile":                       "FROM alpine:3.19\n",
+		"docker/app.Dockerfile":            "FROM debian:bookworm\n",
+		"node_modules/pkg/Dockerfile":      "FROM node\n",
+		"a/b/c/Dockerfile":                 "FROM busybox\n",
+		"docker/Dockerfile.dev":            "FROM alpine:3.19\n",
+		"docker/README.md":                 "FROM nothing\n",
+		".git/Dockerfile":                  "FROM git\n",
+		"services/api/Containerfile":       "FROM fedora\n",
+		"services/api/internal/Dockerfile": "FROM too-deep\n",
+	}
+	for file, content := range files {
+		file = filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	project, err := InspectProject(dir, "git@github.com:gotugboat/tugboat.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDockerfiles := []string{"Dockerfile", "docker/Dockerfile.dev", "docker/app.Dockerfile", "services/api/Containerfile"}
+	if !reflect.DeepEqual(project.Dockerfiles, expectedDockerfiles) {
+		t.Errorf("expected Dockerfiles %v, got %v", expectedDockerfiles, project.Dockerfiles)
+	}
+
+	expectedBaseImages := []string{"alpine:3.19", "debian:bookworm", "fedora"}
+	if !reflect.DeepEqual(project.BaseImages, expectedBaseImages) {
+		t.Errorf("expected base images %v, got %v", expectedBaseImages, project.BaseImages)
+	}
+
+	if project.Namespace != "gotugboat" || project.ImageName != "tugboat" {
+		t.Errorf("expected gotugboat/tugboat, got %v/%v", project.Namespace, project.ImageName)
+	}
+}
+
+func TestRenderConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		values InitValues
+	}{
+		{
+			name: "detected values",
+			values: InitValues{
+				Registry:      "docker.io",
+				Namespace:     "gotugboat",
+				ImageName:     "tugboat",
+				Dockerfile:    "docker/app.Dockerfile",
+				Context:       ".",
+				Architectures: []string{"amd64", "arm64"},
+			},
+		},
+		{
+			name:   "placeholders",
+			values: InitValues{Registry: "docker.io", Dockerfile: "Dockerfile", Context: "."},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := RenderConfig(tc.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the created file must be accepted by the schema
+			if err := validate("tugboat.yaml", content, newSchema(flags.AllFlagGroups()...)); err != nil {
+				t.Errorf("expected a valid config file, got %v\n%s", err, content)
+			}
+		})
+	}
+}
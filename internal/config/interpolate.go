@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"tugboat/internal/pkg/flags"
+	"tugboat/internal/pkg/interpolate"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// credentialKeys are used as they are, a $ is common in passwords and in the names of robot accounts
+// (i.e. robot$ci) and the credentials already refer to a variable or a file with env: and file:
+var credentialKeys = map[string]bool{"user": true, "password": true}
+
+// Interpolate expands the variables of the string values of the configuration file (i.e. $VERSION or
+// ${file:VERSION}). The profiles are skipped as the selected ones are already merged, and the values
+// set by a flag or an environment variable and the credentials are used as they are.
+func Interpolate(ctx context.Context) error {
+	expanded := make(map[string]interface{})
+	for _, key := range viper.AllKeys() {
+		if !viper.InConfig(key) || key == profilesKey || strings.HasPrefix(key, profilesKey+".") {
+			continue
+		}
+		if path := strings.Split(key, "."); credentialKeys[path[len(path)-1]] {
+			continue
+		}
+		if flags.FlagChanged(key) || flags.LookupEnv(key) != "" {
+			continue
+		}
+
+		value, changed, err := interpolateValue(ctx, viper.Get(key))
+		if err != nil {
+			return errors.Wrapf(err, "interpolating %s", key)
+		}
+		if changed {
+			setNested(expanded, strings.Split(key, "."), value)
+		}
+	}
+
+	if len(expanded) == 0 {
+		return nil
+	}
+	return viper.MergeConfigMap(expanded)
+}
+
+// interpolateValue expands the strings of a value, including the ones in lists and maps except the
+// credentials
+func interpolateValue(ctx context.Context, value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "$") {
+			return v, false, nil
+		}
+		expanded, err := interpolate.Expand(ctx, v)
+		return expanded, err == nil, err
+	case []interface{}:
+		changed := false
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded, ok, err := interpolateValue(ctx, item)
+			if err != nil {
+				return nil, false, err
+			}
+			items[i] = expanded
+			changed = changed || ok
+		}
+		return items, changed, nil
+	case map[string]interface{}:
+		changed := false
+		entries := make(map[string]interface{}, len(v))
+		for key, entry := range v {
+			if credentialKeys[key] {
+				entries[key] = entry
+				continue
+			}
+
+			expanded, ok, err := interpolateValue(ctx, entry)
+			if err != nil {
+				return nil, false, err
+			}
+			entries[key] = expanded
+			changed = changed || ok
+		}
+		return entries, changed, nil
+	}
+
+	return value, false, nil
+}
+
+// setNested sets the value at the path, creating the maps leading to it
+func setNested(settings map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := settings[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			settings[key] = child
+		}
+		settings = child
+	}
+	settings[path[len(path)-1]] = value
+}
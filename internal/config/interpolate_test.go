@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("VERSION", "1.2.0")
+	t.Setenv("TEAM", "platform")
+
+	config := `
+image:
+  version: ${VERSION:-dev}
+build:
+  labels:
+    - com.acme.team=$TEAM
+  tags:
+    - '{{.ImageName}}:{{.Version}}'
+images:
+  - image:
+      name: api
+      version: v$VERSION
+profiles:
+  release:
+    image:
+      version: ${RELEASE:?is required}
+`
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Interpolate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := viper.GetString("image.version"); actual != "1.2.0" {
+		t.Errorf("expected the version 1.2.0, got %v", actual)
+	}
+	if actual := viper.GetStringSlice("build.labels"); !reflect.DeepEqual(actual, []string{"com.acme.team=platform"}) {
+		t.Errorf("expected the label to be expanded, got %v", actual)
+	}
+	if actual := viper.GetStringSlice("build.tags"); !reflect.DeepEqual(actual, []string{"{{.ImageName}}:{{.Version}}"}) {
+		t.Errorf("expected the templates to be kept, got %v", actual)
+	}
+
+	images, _ := viper.Get("images").([]interface{})
+	if len(images) != 1 || !strings.Contains(fmt.Sprintf("%v", images[0]), "v1.2.0") {
+		t.Errorf("expected the images to be expanded, got %v", images)
+	}
+}
+
+func TestInterpolate_error(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("VERSION", "")
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("image:\n  version: ${VERSION:?is required}\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Interpolate(context.Background())
+	if err == nil || err.Error() != "interpolating image.version: VERSION: is required" {
+		t.Errorf("expected the missing variable to be reported, got %v", err)
+	}
+}
+
+func TestInterpolate_credentials(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("VERSION", "1.2.0")
+
+	config := `
+image:
+  version: $VERSION
+registry:
+  user: robot$ci
+  password: pa$sword
+registries:
+  - url: ghcr.io
+    user: robot$ghcr
+    password: $(secret)
+promote:
+  target:
+    password: env:TARGET_PASSWORD
+`
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Interpolate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"image.version":           "1.2.0",
+		"registry.user":           "robot$ci",
+		"registry.password":       "pa$sword",
+		"promote.target.password": "env:TARGET_PASSWORD",
+	}
+	for key, value := range expected {
+		if actual := viper.GetString(key); actual != value {
+			t.Errorf("expected %v to be %q, got %q", key, value, actual)
+		}
+	}
+
+	registries, _ := viper.Get("registries").([]interface{})
+	registry, _ := registries[0].(map[string]interface{})
+	if registry["user"] != "robot$ghcr" || registry["password"] != "$(secret)" {
+		t.Errorf("expected the credentials of the registries to be kept, got %v", registry)
+	}
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion is the current layout of the configuration file, a file without a version key has
+// the first layout
+const ConfigVersion = 2
+
+// keyMove moves the value of a key to a new key
+type keyMove struct {
+	from string
+	to   string
+}
+
+// migrations are the changes of the layout from a version to the next one
+var migrations = map[int][]keyMove{
+	1: {
+		{from: "docker", to: "registry"},
+		{from: "publish.official", to: "options.official"},
+	},
+}
+
+// Migrate rewrites a configuration file to the current layout, the comments are kept. The version of
+// the file before the migration is returned.
+func Migrate(content []byte) ([]byte, int, error) {
+	migrated, version, _, err := migrate(content)
+	return migrated, version, err
+}
+
+// migrate rewrites a configuration file to the current layout, reporting if any key was moved
+func migrate(content []byte) ([]byte, int, bool, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, 0, false, errors.Wrap(err, "parsing the config file")
+	}
+
+	// an empty file has nothing to migrate
+	if len(document.Content) == 0 {
+		return content, ConfigVersion, false, nil
+	}
+
+	version, moved, err := migrateDocument(document.Content[0])
+	if err != nil || version == ConfigVersion {
+		return content, version, false, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, 0, false, errors.Wrap(err, "writing the config file")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, 0, false, errors.Wrap(err, "writing the config file")
+	}
+
+	return spaceSections(buffer.Bytes()), version, moved, nil
+}
+
+// migrateConfig reads a configuration file with an older layout again after migrating it, a file
+// without the keys that were renamed is read as it is
+func migrateConfig(v *viper.Viper, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return errors.Wrap(err, "reading the config file")
+	}
+
+	migrated, version, moved, err := migrate(content)
+	if err != nil {
+		return errors.Wrap(err, file)
+	}
+	if !moved {
+		return nil
+	}
+
+	log.Warnf("%s has the version %d layout, run 'tugboat config migrate' to update it to version %d", file, version, ConfigVersion)
+
+	v.SetConfigType("yaml")
+	return v.ReadConfig(bytes.NewReader(migrated))
+}
+
+// migrateDocument moves the keys of a document to the current layout, returning the version it had
+// and if any key was moved
+func migrateDocument(root *yaml.Node) (int, bool, error) {
+	if root.Kind != yaml.MappingNode {
+		// the validation reports a document that is not a map
+		return ConfigVersion, false, nil
+	}
+
+	version, err := documentVersion(root)
+	if err != nil || version == ConfigVersion {
+		return version, false, err
+	}
+
+	// the profiles have the keys of the configuration file
+	roots := []*yaml.Node{root}
+	if profiles := mappingValue(root, profilesKey); profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 1; i < len(profiles.Content); i += 2 {
+			if profiles.Content[i].Kind == yaml.MappingNode {
+				roots = append(roots, profiles.Content[i])
+			}
+		}
+	}
+
+	moved := false
+	for from := version; from < ConfigVersion; from++ {
+		for _, move := range migrations[from] {
+			for _, node := range roots {
+				ok, err := moveKey(node, move)
+				if err != nil {
+					return version, false, err
+				}
+				moved = moved || ok
+			}
+		}
+	}
+
+	setVersion(root)
+	return version, moved, nil
+}
+
+// documentVersion returns the layout version of a document, an error is returned for the versions
+// this release does not support
+func documentVersion(root *yaml.Node) (int, error) {
+	node := mappingValue(root, flags.ConfigVersionName)
+	if node == nil {
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(node.Value)
+	if err != nil || node.Kind != yaml.ScalarNode || version < 1 || version > ConfigVersion {
+		return 0, errors.Errorf("the config version %q is not supported, this release of tugboat supports the versions 1 to %d", node.Value, ConfigVersion)
+	}
+	return version, nil
+}
+
+// moveKey moves the value of a key, the maps left empty are removed. It reports if the key was set.
+func moveKey(root *yaml.Node, move keyMove) (bool, error) {
+	// a renamed section keeps its place and comments
+	if !strings.Contains(move.from+move.to, ".") && mappingValue(root, move.to) == nil {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == move.from {
+				root.Content[i].Value = move.to
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	value := removeKey(root, strings.Split(move.from, "."))
+	if value == nil {
+		return false, nil
+	}
+
+	return true, setKey(root, strings.Split(move.to, "."), value, move)
+}
+
+// removeKey removes a key from a map and returns its value, nil is returned when the key is not set
+func removeKey(node *yaml.Node, path []string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+
+		value := node.Content[i+1]
+		if len(path) > 1 {
+			if value.Kind != yaml.MappingNode {
+				return nil
+			}
+			removed := removeKey(value, path[1:])
+			if removed == nil || len(value.Content) > 0 {
+				return removed
+			}
+			value = removed
+		}
+
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		return value
+	}
+	return nil
+}
+
+// setKey sets a key of a map, creating the maps leading to it. The keys of a map are added to an
+// existing map, a value set at both keys is an error.
+func setKey(node *yaml.Node, path []string, value *yaml.Node, move keyMove) error {
+	existing := mappingValue(node, path[0])
+	if existing == nil {
+		for i := len(path) - 1; i > 0; i-- {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{scalarNode(path[i]), value}}
+		}
+		node.Content = append(node.Content, scalarNode(path[0]), value)
+		return nil
+	}
+
+	if len(path) > 1 && existing.Kind == yaml.MappingNode {
+		return setKey(existing, path[1:], value, move)
+	}
+
+	if len(path) == 1 && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if err := setKey(existing, []string{value.Content[i].Value}, value.Content[i+1], move); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return errors.Errorf("%s and %s are both set, keep one of them to migrate the config file", move.from, move.to)
+}
+
+// setVersion sets the version key to the current version, it is added as the first key of the file
+func setVersion(root *yaml.Node) {
+	if node := mappingValue(root, flags.ConfigVersionName); node != nil {
+		node.Value = strconv.Itoa(ConfigVersion)
+		node.Tag = "!!int"
+		node.Style = 0
+		return
+	}
+
+	key := scalarNode(flags.ConfigVersionName)
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(ConfigVersion)}
+
+	// the comment at the top of the file stays at the top
+	if len(root.Content) > 0 {
+		key.HeadComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// mappingValue returns the value of a key of a map, nil is returned when the key is not set
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// spaceSections adds the blank lines between the top-level sections, which are not kept when the
+// file is written
+func spaceSections(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+
+	var spaced []string
+	for i, line := range lines {
+		if i > 0 && isTopLevel(line) && !isTopLevel(lines[i-1]) && lines[i-1] != "" {
+			spaced = append(spaced, "")
+		}
+		spaced = append(spaced, line)
+	}
+	return []byte(strings.Join(spaced, "\n"))
+}
+
+func isTopLevel(line string) bool {
+	return line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-")
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/spf13/viper"
+)
+
+const versionOneConfig = `# tugboat.yaml
+options:
+  debug: true
+
+# the registry the images are pushed to
+docker:
+  url: docker.io
+  namespace: acme # the organization
+publish:
+  official: true
+profiles:
+  release:
+    publish:
+      official: false
+`
+
+func TestMigrate(t *testing.T) {
+	migrated, version, err := Migrate([]byte(versionOneConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 1 {
+		t.Errorf("expected the version 1, got %v", version)
+	}
+
+	expected := `# tugboat.yaml
+version: 2
+options:
+  debug: true
+  official: true
+
+# the registry the images are pushed to
+registry:
+  url: docker.io
+  namespace: acme # the organization
+
+profiles:
+  release:
+    options:
+      official: false
+`
+	if string(migrated) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, migrated)
+	}
+
+	// the migrated file is valid and has nothing left to migrate
+	if err := validate("tugboat.yaml", migrated, newSchema(flags.AllFlagGroups()...)); err != nil {
+		t.Errorf("expected the migrated file to be valid, got %v", err)
+	}
+	if _, version, err := Migrate(migrated); err != nil || version != ConfigVersion {
+		t.Errorf("expected the version %v, got %v, %v", ConfigVersion, version, err)
+	}
+}
+
+func TestMigrate_errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "newer version",
+			content:  "version: 3\n",
+			expected: `the config version "3" is not supported, this release of tugboat supports the versions 1 to 2`,
+		},
+		{
+			name:     "invalid version",
+			content:  "version: latest\n",
+			expected: `the config version "latest" is not supported`,
+		},
+		{
+			name:     "both keys are set",
+			content:  "publish:\n  official: true\noptions:\n  official: false\n",
+			expected: "publish.official and options.official are both set",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Migrate([]byte(tc.content))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected the error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_migrate(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"tugboat.yaml": versionOneConfig,
+	})
+
+	if err := LoadConfig(filepath.Join(dir, "tugboat.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !viper.GetBool("options.official") {
+		t.Error("expected publish.official to be read as options.official")
+	}
+	if actual := viper.GetString("registry.namespace"); actual != "acme" {
+		t.Errorf("expected docker.namespace to be read as registry.namespace, got %v", actual)
+	}
+}
+
+func TestLoadConfig_unsupportedVersion(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"tugboat.yaml": "version: 3\n",
+	})
+
+	if err := LoadConfig(filepath.Join(dir, "tugboat.yaml")); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
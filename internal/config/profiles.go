@@ -0,0 +1,199 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"tugboat/internal/pkg/git"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	// profilesKey is the key of the profiles in the configuration file, each profile is a part of
+	// the configuration merged over it when the profile is applied
+	profilesKey = "profiles"
+	// whenKey is the key of the conditions selecting a profile automatically
+	whenKey = "when"
+)
+
+// GitRef is what the profiles are automatically selected by
+type GitRef struct {
+	Branch string
+	Tag    string
+}
+
+// profileConditions select a profile when the branch or the tag matches one of the patterns and
+// every environment variable matches its pattern (i.e. GITHUB_EVENT_NAME: pull_request). The
+// patterns are globs (i.e. release/*, v*).
+type profileConditions struct {
+	Branches []string          `mapstructure:"branches"`
+	Tags     []string          `mapstructure:"tags"`
+	Env      map[string]string `mapstructure:"env"`
+}
+
+// ApplyProfiles merges the profiles of the configuration file over the loaded configuration, so
+// the flags and environment variables still take precedence. When no profiles are named, the
+// profiles whose conditions match the git branch or tag are applied. The names of the applied
+// profiles are returned.
+func ApplyProfiles(names []string) ([]string, error) {
+	if viper.Get(profilesKey) == nil {
+		if len(names) > 0 {
+			return nil, errors.Errorf("the profile %s is not defined, the configuration file has no profiles", names[0])
+		}
+		return nil, nil
+	}
+
+	// a comma separated list is accepted from the environment (i.e. TUGBOAT_PROFILE=release,ghcr)
+	var split []string
+	for _, name := range names {
+		split = append(split, strings.Split(name, ",")...)
+	}
+
+	ctx := context.Background()
+	return applyProfiles(split, GitRef{Branch: git.Branch(ctx), Tag: git.ExactTag(ctx)})
+}
+
+// appliedProfiles are the names of the profiles merged over the configuration
+var appliedProfiles []string
+
+// profileKeys are the keys set by the applied profiles and the profile that set them last
+var profileKeys = make(map[string]string)
+
+// AppliedProfiles returns the names of the profiles merged over the configuration
+func AppliedProfiles() []string {
+	return appliedProfiles
+}
+
+func applyProfiles(names []string, ref GitRef) ([]string, error) {
+	appliedProfiles = nil
+	profileKeys = make(map[string]string)
+	profiles := viper.GetStringMap(profilesKey)
+
+	var available []string
+	for name := range profiles {
+		available = append(available, name)
+	}
+	sort.Strings(available)
+
+	if len(names) == 0 {
+		for _, name := range available {
+			matches, err := profileMatches(name, ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, "profile %s", name)
+			}
+			if matches {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var applied []string
+	for _, name := range names {
+		// the keys of the configuration file are case insensitive
+		name = strings.ToLower(strings.TrimSpace(name))
+		profile, ok := profiles[name]
+		if !ok {
+			return nil, errors.Errorf("the profile %s is not defined (available: %s)", name, strings.Join(available, ", "))
+		}
+
+		values, ok := profile.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("the profile %s must be a map", name)
+		}
+
+		overlay := make(map[string]interface{})
+		for key, value := range values {
+			if key != whenKey {
+				overlay[key] = value
+			}
+		}
+
+		if err := viper.MergeConfigMap(overlay); err != nil {
+			return nil, errors.Wrapf(err, "applying the profile %s", name)
+		}
+		recordKeys(profileKeys, name, "", overlay)
+		applied = append(applied, name)
+	}
+
+	appliedProfiles = applied
+	return applied, nil
+}
+
+// profileMatches returns true when the conditions of the profile are met, a profile without
+// conditions is only applied when it is named
+func profileMatches(name string, ref GitRef) (bool, error) {
+	key := strings.Join([]string{profilesKey, name, whenKey}, ".")
+	if !viper.IsSet(key) {
+		return false, nil
+	}
+
+	var conditions profileConditions
+	if err := viper.UnmarshalKey(key, &conditions); err != nil {
+		return false, errors.Wrap(err, "reading the conditions")
+	}
+
+	if len(conditions.Branches) > 0 || len(conditions.Tags) > 0 {
+		branchMatches, err := matchesAny(conditions.Branches, ref.Branch)
+		if err != nil {
+			return false, err
+		}
+		tagMatches, err := matchesAny(conditions.Tags, ref.Tag)
+		if err != nil {
+			return false, err
+		}
+		if !branchMatches && !tagMatches {
+			return false, nil
+		}
+	}
+
+	for name, pattern := range conditions.Env {
+		// the keys are lower cased when the configuration is read, environment variables are upper case
+		matches, err := path.Match(pattern, os.Getenv(strings.ToUpper(name)))
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchesAny returns true when the value matches one of the glob patterns, an empty value
+// matches nothing
+func matchesAny(patterns []string, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	for _, pattern := range patterns {
+		matches, err := path.Match(pattern, value)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordKeys records the keys of the values set by a profile or a file, maps are merged so each of
+// their keys is recorded
+func recordKeys(keys map[string]string, source string, prefix string, values map[string]interface{}) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			recordKeys(keys, source, key, nested)
+			continue
+		}
+		keys[key] = source
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const profilesConfig = `
+registry:
+  url: docker.io
+  namespace: acme
+build:
+  push: false
+  tags:
+    - '{{.ImageName}}:{{.Version}}'
+profiles:
+  release:
+    when:
+      tags: [v*]
+    build:
+      push: true
+  main:
+    when:
+      branches: [main, release/*]
+    build:
+      tags:
+        - '{{.ImageName}}:edge'
+  pr:
+    when:
+      env:
+        GITHUB_EVENT_NAME: pull_request
+    registry:
+      url: ghcr.io
+  local:
+    registry:
+      url: localhost:5000
+`
+
+func readProfilesConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(profilesConfig)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_applyProfiles(t *testing.T) {
+	testCases := []struct {
+		name             string
+		names            []string
+		ref              GitRef
+		env              map[string]string
+		expectedProfiles []string
+		expectedUrl      string
+		expectedPush     bool
+		expectedTags     []string
+	}{
+		{
+			name:         "no matching profile",
+			ref:          GitRef{Branch: "feature/login"},
+			expectedUrl:  "docker.io",
+			expectedTags: []string{"{{.ImageName}}:{{.Version}}"},
+		},
+		{
+			name:             "branch pattern",
+			ref:              GitRef{Branch: "release/1.2"},
+			expectedProfiles: []string{"main"},
+			expectedUrl:      "docker.io",
+			expectedTags:     []string{"{{.ImageName}}:edge"},
+		},
+		{
+			name:             "tag pattern",
+			ref:              GitRef{Branch: "main", Tag: "v1.2.0"},
+			expectedProfiles: []string{"main", "release"},
+			expectedUrl:      "docker.io",
+			expectedPush:     true,
+			expectedTags:     []string{"{{.ImageName}}:edge"},
+		},
+		{
+			name:             "environment",
+			env:              map[string]string{"GITHUB_EVENT_NAME": "pull_request"},
+			expectedProfiles: []string{"pr"},
+			expectedUrl:      "ghcr.io",
+			expectedTags:     []string{"{{.ImageName}}:{{.Version}}"},
+		},
+		{
+			name:             "named profiles replace the automatic selection",
+			names:            []string{"Local", "release"},
+			ref:              GitRef{Branch: "main"},
+			expectedProfiles: []string{"local", "release"},
+			expectedUrl:      "localhost:5000",
+			expectedPush:     true,
+			expectedTags:     []string{"{{.ImageName}}:{{.Version}}"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GITHUB_EVENT_NAME", "")
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+			readProfilesConfig(t)
+
+			applied, err := applyProfiles(tc.names, tc.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(applied, tc.expectedProfiles) {
+				t.Errorf("expected the profiles %v, got %v", tc.expectedProfiles, applied)
+			}
+			if actual := viper.GetString("registry.url"); actual != tc.expectedUrl {
+				t.Errorf("expected the url %v, got %v", tc.expectedUrl, actual)
+			}
+			if actual := viper.GetBool("build.push"); actual != tc.expectedPush {
+				t.Errorf("expected push %v, got %v", tc.expectedPush, actual)
+			}
+			if actual := viper.GetStringSlice("build.tags"); !reflect.DeepEqual(actual, tc.expectedTags) {
+				t.Errorf("expected the tags %v, got %v", tc.expectedTags, actual)
+			}
+			// the profiles are merged, the values they do not set are kept
+			if actual := viper.GetString("registry.namespace"); actual != "acme" {
+				t.Errorf("expected the namespace to be kept, got %v", actual)
+			}
+		})
+	}
+}
+
+func Test_applyProfiles_unknown(t *testing.T) {
+	readProfilesConfig(t)
+
+	if _, err := applyProfiles([]string{"staging"}, GitRef{}); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestApplyProfiles_withoutProfiles(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	applied, err := ApplyProfiles(nil)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("expected no profiles, got %v, %v", applied, err)
+	}
+
+	if _, err := ApplyProfiles([]string{"release"}); err == nil {
+		t.Error("expected an error when the config file has no profiles")
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"tugboat/internal/pkg/flags"
+)
+
+// kind is the type of a value in the configuration file
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindDuration
+	// kindList is a list of values, a comma separated string is accepted for a list of strings
+	kindList
+	kindMap
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindBool:
+		return "a boolean"
+	case kindInt:
+		return "an integer"
+	case kindDuration:
+		return "a duration (i.e. 10m)"
+	case kindList:
+		return "a list"
+	case kindMap:
+		return "a map"
+	}
+	return "a string"
+}
+
+// field describes a key of the configuration file
+type field struct {
+	kind kind
+	// values are the accepted values of a scalar, any value is accepted when empty
+	values []string
+	// fields are the keys of a map
+	fields map[string]*field
+	// items describes the values of a list
+	items *field
+	// entries describes the values of a map with any keys (i.e. the profiles)
+	entries *field
+}
+
+// newSchema returns the keys of the configuration file, which are the config names of the flags
+func newSchema(groups ...flags.FlagGroup) *field {
+	root := &field{kind: kindMap, fields: make(map[string]*field)}
+
+	for _, group := range groups {
+		for _, flag := range group.Flags() {
+			if flag == nil || flag.ConfigName == "" {
+				continue
+			}
+
+			f := fieldOf(reflect.TypeOf(flag.Value))
+			f.values = flag.Values
+			root.add(strings.Split(flag.ConfigName, "."), f)
+		}
+	}
+
+	// each entry of the images list has its own sections, with the same keys as the top-level ones
+	entry := &field{kind: kindMap, fields: make(map[string]*field)}
+	for _, section := range flags.ImageSections {
+		if f, ok := root.fields[section]; ok {
+			entry.fields[section] = f
+		}
+	}
+	if len(entry.fields) > 0 {
+		root.fields[flags.ImagesConfigName] = &field{kind: kindList, items: entry}
+	}
+
+	// a profile has the keys of the configuration file and the conditions selecting it
+	profile := &field{kind: kindMap, fields: make(map[string]*field)}
+	for key, f := range root.fields {
+		profile.fields[key] = f
+	}
+	profile.fields[whenKey] = fieldOf(reflect.TypeOf(profileConditions{}))
+	root.fields[profilesKey] = &field{kind: kindMap, entries: profile}
+
+	root.fields[flags.ConfigVersionName] = &field{kind: kindInt}
+
+	// the files the configuration is based on
+	root.fields[extendsKey] = &field{kind: kindList, items: &field{kind: kindString}}
+
+	return root
+}
+
+// add places the field at the path, creating the maps leading to it
+func (f *field) add(path []string, value *field) {
+	if len(path) == 1 {
+		f.fields[path[0]] = value
+		return
+	}
+
+	child, ok := f.fields[path[0]]
+	if !ok || child.kind != kindMap {
+		child = &field{kind: kindMap, fields: make(map[string]*field)}
+		f.fields[path[0]] = child
+	}
+	child.add(path[1:], value)
+}
+
+// fieldOf describes the values of a type, structs are described by their mapstructure tags
+func fieldOf(t reflect.Type) *field {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return &field{kind: kindDuration}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &field{kind: kindBool}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &field{kind: kindInt}
+	case reflect.Slice:
+		return &field{kind: kindList, items: fieldOf(t.Elem())}
+	case reflect.Map:
+		return &field{kind: kindMap, entries: fieldOf(t.Elem())}
+	case reflect.Struct:
+		f := &field{kind: kindMap, fields: make(map[string]*field)}
+		for i := 0; i < t.NumField(); i++ {
+			if name := t.Field(i).Tag.Get("mapstructure"); name != "" {
+				f.fields[name] = fieldOf(t.Field(i).Type)
+			}
+		}
+		return f
+	}
+
+	return &field{kind: kindString}
+}
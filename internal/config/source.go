@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/spf13/viper"
+)
+
+// SourceKind is where the value of a key comes from, in the order of precedence
+type SourceKind string
+
+const (
+	SourceFlag    SourceKind = "flag"
+	SourceEnv     SourceKind = "env"
+	SourceProfile SourceKind = "profile"
+	SourceFile    SourceKind = "file"
+	SourceDefault SourceKind = "default"
+)
+
+// Source is where the value of a key comes from, Name is the flag, environment variable,
+// profile or file the value is read from
+type Source struct {
+	Kind SourceKind
+	Name string
+}
+
+func (s Source) String() string {
+	if s.Name == "" {
+		return string(s.Kind)
+	}
+	return fmt.Sprintf("%s %s", s.Kind, s.Name)
+}
+
+// ValueSource returns where the value of the flag comes from, following the precedence of the
+// flags over the environment variables, the profiles, the configuration file and the defaults
+func ValueSource(flag *flags.Flag) Source {
+	key := flag.ConfigName
+
+	if flag.Name != "" && flags.FlagChanged(key) {
+		return Source{Kind: SourceFlag, Name: "--" + flag.Name}
+	}
+
+	if env := flags.LookupEnv(key); env != "" {
+		return Source{Kind: SourceEnv, Name: env}
+	}
+
+	if profile := sourceOf(profileKeys, key); profile != "" {
+		return Source{Kind: SourceProfile, Name: profile}
+	}
+
+	// the keys of the extended files are reported with the file that set them last
+	if file := sourceOf(fileKeys, key); file != "" {
+		return Source{Kind: SourceFile, Name: file}
+	}
+
+	if viper.InConfig(key) {
+		return Source{Kind: SourceFile, Name: ConfigFileUsed()}
+	}
+
+	return Source{Kind: SourceDefault}
+}
+
+// sourceOf returns the profile or file that set the key or one of its keys (i.e. registry.credential-provider.command)
+func sourceOf(keys map[string]string, key string) string {
+	if source, ok := keys[key]; ok {
+		return source
+	}
+	for sourceKey, source := range keys {
+		if strings.HasPrefix(sourceKey, key+".") {
+			return source
+		}
+	}
+	return ""
+}
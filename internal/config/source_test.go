@@ -0,0 +1,78 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/flags"
+)
+
+func TestValueSource(t *testing.T) {
+	readProfilesConfig(t)
+	t.Setenv("GITHUB_EVENT_NAME", "")
+	t.Setenv("BUILD_FILE", "Dockerfile.ci")
+
+	if _, err := applyProfiles([]string{"release"}, GitRef{}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		flag     *flags.Flag
+		expected Source
+	}{
+		{flag: &flags.PushFlag, expected: Source{Kind: SourceProfile, Name: "release"}},
+		{flag: &flags.RegistryUrlFlag, expected: Source{Kind: SourceFile}},
+		{flag: &flags.FileFlag, expected: Source{Kind: SourceEnv, Name: "BUILD_FILE"}},
+		{flag: &flags.NoCacheFlag, expected: Source{Kind: SourceDefault}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.flag.ConfigName, func(t *testing.T) {
+			if actual := ValueSource(tc.flag); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if !strings.HasPrefix(Source{Kind: SourceFlag, Name: "--push"}.String(), "flag --push") {
+		t.Error("expected the kind and name of the source")
+	}
+}
+
+func TestValueSource_extends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"defaults/base.yaml": `
+registry:
+  url: docker.io
+  namespace: acme
+  credential-provider:
+    command: ecr-credential-provider
+`,
+		"tugboat.yaml": `
+extends: defaults/base.yaml
+registry:
+  namespace: app-team
+`,
+	})
+
+	configFile := filepath.Join(dir, "tugboat.yaml")
+	if err := LoadConfig(configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		flag     *flags.Flag
+		expected Source
+	}{
+		{flag: &flags.RegistryUrlFlag, expected: Source{Kind: SourceFile, Name: filepath.Join(dir, "defaults", "base.yaml")}},
+		{flag: &flags.RegistryNamespaceFlag, expected: Source{Kind: SourceFile, Name: configFile}},
+		{flag: &flags.RegistryCredentialProviderFlag, expected: Source{Kind: SourceFile, Name: filepath.Join(dir, "defaults", "base.yaml")}},
+		{flag: &flags.NoCacheFlag, expected: Source{Kind: SourceDefault}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.flag.ConfigName, func(t *testing.T) {
+			if actual := ValueSource(tc.flag); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tugboat/internal/pkg/flags"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem with a key of the configuration file
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Key, e.Message)
+}
+
+// ValidationErrors are all the problems found in a configuration file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := []string{fmt.Sprintf("the configuration file is invalid (%d %s)", len(e), pluralize("error", len(e)))}
+	for _, err := range e {
+		messages = append(messages, "  "+err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate checks the configuration file and the files it extends against the keys of the flags,
+// reporting unknown keys, values of the wrong type and values that are not accepted.
+// ValidationErrors is returned when one of the files is invalid.
+func Validate(file string) error {
+	errs, err := validateExtended(file, newSchema(flags.AllFlagGroups()...), make(map[string]bool))
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateExtended validates the file and the files it extends, the files already validated are
+// skipped since a file extending itself is reported when the configuration is loaded
+func validateExtended(file string, schema *field, validated map[string]bool) (ValidationErrors, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving %s", file)
+	}
+	if validated[path] {
+		return nil, nil
+	}
+	validated[path] = true
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the config file")
+	}
+
+	var errs ValidationErrors
+	if err := validate(file, content, schema); err != nil {
+		var fileErrs ValidationErrors
+		if !errors.As(err, &fileErrs) {
+			return nil, err
+		}
+		errs = append(errs, fileErrs...)
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", file)
+	}
+
+	extends, err := extendsOf(document[extendsKey])
+	if err != nil {
+		return nil, errors.Wrap(err, file)
+	}
+
+	for _, extended := range extends {
+		// the paths are relative to the file extending them
+		if !filepath.IsAbs(extended) {
+			extended = filepath.Join(filepath.Dir(file), extended)
+		}
+
+		extendedErrs, err := validateExtended(extended, schema, validated)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, extendedErrs...)
+	}
+
+	return errs, nil
+}
+
+func validate(file string, content []byte, schema *field) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return errors.Wrapf(err, "parsing %s", file)
+	}
+
+	// an empty file is a valid configuration
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	// an older layout is checked after it is migrated, the keys keep their lines
+	if _, _, err := migrateDocument(document.Content[0]); err != nil {
+		return errors.Wrap(err, file)
+	}
+
+	v := &validator{file: file}
+	v.check(document.Content[0], schema, "")
+
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+type validator struct {
+	file   string
+	errors ValidationErrors
+}
+
+func (v *validator) report(node *yaml.Node, key string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check validates the node against the field, key is the path of the node (i.e. registries[0].url)
+func (v *validator) check(node *yaml.Node, f *field, key string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// an empty value leaves the default
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch f.kind {
+	case kindMap:
+		v.checkMap(node, f, key)
+	case kindList:
+		v.checkList(node, f, key)
+	default:
+		v.checkScalar(node, f, key)
+	}
+}
+
+func (v *validator) checkMap(node *yaml.Node, f *field, key string) {
+	if node.Kind != yaml.MappingNode {
+		v.report(node, key, "expected %s", f.kind)
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		// merge keys (<<: *defaults) are checked where the anchor is defined
+		if keyNode.Tag == "!!merge" {
+			continue
+		}
+
+		name := strings.ToLower(keyNode.Value)
+		path := name
+		if key != "" {
+			path = key + "." + name
+		}
+
+		if f.entries != nil {
+			v.check(valueNode, f.entries, path)
+			continue
+		}
+
+		child, ok := f.fields[name]
+		if !ok {
+			if suggestion := suggest(name, f.fields); suggestion != "" {
+				v.report(keyNode, path, "unknown key, did you mean %q?", suggestion)
+			} else {
+				v.report(keyNode, path, "unknown key")
+			}
+			continue
+		}
+
+		v.check(valueNode, child, path)
+	}
+}
+
+func (v *validator) checkList(node *yaml.Node, f *field, key string) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			v.check(item, f.items, fmt.Sprintf("%s[%d]", key, i))
+		}
+	case yaml.ScalarNode:
+		// a list of strings can be a comma separated string
+		if f.items.kind != kindString {
+			v.report(node, key, "expected %s", f.kind)
+			return
+		}
+		for _, value := range strings.Split(node.Value, ",") {
+			v.checkValue(node, f.items, key, strings.TrimSpace(value))
+		}
+	default:
+		v.report(node, key, "expected %s", f.kind)
+	}
+}
+
+func (v *validator) checkScalar(node *yaml.Node, f *field, key string) {
+	if node.Kind != yaml.ScalarNode {
+		v.report(node, key, "expected %s", f.kind)
+		return
+	}
+
+	// the value is only known once its variables are expanded (i.e. ${PUSH:-false})
+	if hasVariables(node.Value) {
+		return
+	}
+
+	switch f.kind {
+	case kindBool:
+		if _, err := strconv.ParseBool(node.Value); err != nil {
+			v.report(node, key, "expected %s, got %q", f.kind, node.Value)
+			return
+		}
+	case kindInt:
+		if _, err := strconv.Atoi(node.Value); err != nil {
+			v.report(node, key, "expected %s, got %q", f.kind, node.Value)
+			return
+		}
+	case kindDuration:
+		if _, err := time.ParseDuration(node.Value); err != nil && node.Value != "0" {
+			v.report(node, key, "expected %s, got %q", f.kind, node.Value)
+			return
+		}
+	}
+
+	v.checkValue(node, f, key, node.Value)
+}
+
+// checkValue reports a value that is not one of the accepted values of the field
+func (v *validator) checkValue(node *yaml.Node, f *field, key string, value string) {
+	if len(f.values) == 0 || hasVariables(value) {
+		return
+	}
+
+	for _, accepted := range f.values {
+		if strings.EqualFold(value, accepted) {
+			return
+		}
+	}
+
+	v.report(node, key, "invalid value %q, expected one of %s", value, strings.Join(f.values, ", "))
+}
+
+// hasVariables returns true when the value has variables expanded by Interpolate
+func hasVariables(value string) bool {
+	return strings.Contains(value, "$")
+}
+
+// suggest returns the known key closest to an unknown key, an empty string is returned when none is close
+func suggest(name string, fields map[string]*field) string {
+	var keys []string
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	normalized := strings.ReplaceAll(name, "_", "-")
+
+	best, bestDistance := "", 3
+	for _, key := range keys {
+		if distance := levenshtein(normalized, key); distance < bestDistance {
+			best, bestDistance = key, distance
+		}
+	}
+	return best
+}
+
+// levenshtein returns the number of single character edits between the strings
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func pluralize(word string, number int) string {
+	if number == 1 {
+		return word
+	}
+	return word + "s"
+}
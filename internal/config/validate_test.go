@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"tugboat/internal/pkg/flags"
+)
+
+func TestValidate_example(t *testing.T) {
+	if err := Validate("../../example.tugboat.yaml"); err != nil {
+		t.Errorf("expected the example configuration to be valid, got %v", err)
+	}
+}
+
+func Test_validate(t *testing.T) {
+	schema := newSchema(flags.AllFlagGroups()...)
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected []ValidationError
+	}{
+		{
+			name:    "empty file",
+			content: "",
+		},
+		{
+			name: "valid",
+			content: `
+image:
+  name: app
+  supported-architectures: amd64,arm64
+build:
+  no-cache: true
+  args:
+    - FOO=bar
+extends:
+  - defaults/tugboat.yaml
+registry:
+  rate-limit-wait: 10m
+  credential-provider:
+    command: ecr-credential-provider
+driver:
+  name: Docker
+prune:
+  keep-last: 5
+  older-than:
+`,
+		},
+		{
+			name: "variables",
+			content: `
+build:
+  push: ${PUSH:-false}
+driver:
+  name: $DRIVER
+image:
+  supported-architectures: ${ARCHITECTURES}
+`,
+		},
+		{
+			name: "unknown keys",
+			content: `
+build:
+  no_cache: true
+image:
+  supported-architecture:
+    - amd64
+colour: blue
+`,
+			expected: []ValidationError{
+				{Line: 3, Column: 3, Key: "build.no_cache", Message: `unknown key, did you mean "no-cache"?`},
+				{Line: 5, Column: 3, Key: "image.supported-architecture", Message: `unknown key, did you mean "supported-architectures"?`},
+				{Line: 7, Column: 1, Key: "colour", Message: "unknown key"},
+			},
+		},
+		{
+			name: "wrong types",
+			content: `
+build: true
+tag:
+  push: sometimes
+prune:
+  keep-last: [1]
+registry:
+  rate-limit-wait: 600
+`,
+			expected: []ValidationError{
+				{Line: 2, Column: 8, Key: "build", Message: "expected a map"},
+				{Line: 4, Column: 9, Key: "tag.push", Message: `expected a boolean, got "sometimes"`},
+				{Line: 6, Column: 14, Key: "prune.keep-last", Message: "expected an integer"},
+				{Line: 8, Column: 20, Key: "registry.rate-limit-wait", Message: `expected a duration (i.e. 10m), got "600"`},
+			},
+		},
+		{
+			name: "invalid values",
+			content: `
+driver:
+  name: podman
+`,
+			expected: []ValidationError{
+				{Line: 3, Column: 9, Key: "driver.name", Message: `invalid value "podman", expected one of auto, docker`},
+			},
+		},
+		{
+			name: "list of maps",
+			content: `
+registries:
+  - url: ghcr.io
+    pasword: secret
+  - ghcr.io
+`,
+			expected: []ValidationError{
+				{Line: 4, Column: 5, Key: "registries[0].pasword", Message: `unknown key, did you mean "password"?`},
+				{Line: 5, Column: 5, Key: "registries[1]", Message: "expected a map"},
+			},
+		},
+		{
+			name: "images",
+			content: `
+images:
+  - image:
+      name: api
+    build:
+      file: api/Dockerfile
+      push: maybe
+    registry:
+      url: ghcr.io
+`,
+			expected: []ValidationError{
+				{Line: 7, Column: 13, Key: "images[0].build.push", Message: `expected a boolean, got "maybe"`},
+				{Line: 8, Column: 5, Key: "images[0].registry", Message: "unknown key"},
+			},
+		},
+		{
+			name: "profiles",
+			content: `
+profiles:
+  release:
+    when:
+      tags: [v*]
+      env:
+        CI: "true"
+    build:
+      push: true
+  pr:
+    when:
+      branch: main
+    regsitry:
+      url: ghcr.io
+`,
+			expected: []ValidationError{
+				{Line: 12, Column: 7, Key: "profiles.pr.when.branch", Message: `unknown key, did you mean "branches"?`},
+				{Line: 13, Column: 5, Key: "profiles.pr.regsitry", Message: `unknown key, did you mean "registry"?`},
+			},
+		},
+		{
+			name: "older version",
+			content: `
+docker:
+  url: ghcr.io
+publish:
+  official: maybe
+`,
+			expected: []ValidationError{
+				{Line: 5, Column: 13, Key: "options.official", Message: `expected a boolean, got "maybe"`},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validate("tugboat.yaml", []byte(tc.content), schema)
+
+			var actual ValidationErrors
+			if err != nil && !errors.As(err, &actual) {
+				t.Fatalf("An unexpected error occurred: %v", err)
+			}
+
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected %v errors, got %v", len(tc.expected), err)
+			}
+
+			for i, expected := range tc.expected {
+				expected.File = "tugboat.yaml"
+				if actual[i] != expected {
+					t.Errorf("expected %v, got %v", expected, actual[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_validate_invalidYaml(t *testing.T) {
+	err := validate("tugboat.yaml", []byte("image: [amd64"), newSchema())
+
+	var validationErrors ValidationErrors
+	if err == nil || errors.As(err, &validationErrors) {
+		t.Errorf("expected a parsing error, got %v", err)
+	}
+}
+
+func TestValidate_extends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"defaults/base.yaml": `
+registry:
+  url: docker.io
+build:
+  no_cache: true
+`,
+		"defaults/arm.yaml": `
+extends: base.yaml
+image:
+  supported-architectures: [arm64]
+  colour: blue
+`,
+		"tugboat.yaml": `
+extends:
+  - defaults/arm.yaml
+  - defaults/base.yaml
+image:
+  name: app
+`,
+	})
+
+	err := Validate(filepath.Join(dir, "tugboat.yaml"))
+
+	var actual ValidationErrors
+	if !errors.As(err, &actual) {
+		t.Fatalf("expected the errors of the extended files, got %v", err)
+	}
+
+	// each file is validated once, even when it is extended more than once
+	expected := []ValidationError{
+		{File: filepath.Join(dir, "defaults", "arm.yaml"), Line: 5, Column: 3, Key: "image.colour", Message: "unknown key"},
+		{File: filepath.Join(dir, "defaults", "base.yaml"), Line: 5, Column: 3, Key: "build.no_cache", Message: `unknown key, did you mean "no-cache"?`},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v errors, got %v", len(expected), err)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], actual[i])
+		}
+	}
+}
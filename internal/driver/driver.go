@@ -60,6 +60,17 @@ type BuildOptions struct {
 	Pull       bool
 	NoCache    bool
 	Push       bool
+	Target     string
+	Labels     []string
+	Platform   string
+	Network    string
+	AddHosts   []string
+	ShmSize    string
+	Ulimits    []string
+	Ssh        []string
+	Secrets    []string
+	CacheFrom  []string
+	CacheTo    []string
 }
 
 type PushOptions struct {
@@ -73,5 +84,6 @@ type ManifestCreateOptions struct {
 }
 
 type ManifestPushOptions struct {
-	Purge bool
+	Purge    bool
+	Insecure bool
 }
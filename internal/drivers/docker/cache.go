@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"tugboat/internal/pkg/reference"
+
+	"github.com/pkg/errors"
+)
+
+// The supported build cache types
+const (
+	cacheTypeRegistry = "registry"
+	cacheTypeLocal    = "local"
+	cacheTypeInline   = "inline"
+)
+
+// cacheTagSuffix is the tag used for per-arch registry caches (i.e. image:amd64-buildcache)
+const cacheTagSuffix = "buildcache"
+
+type cacheDirection int
+
+const (
+	cacheImport cacheDirection = iota
+	cacheExport
+)
+
+// cacheDefaults holds the values used to complete a cache spec that only defines a type
+type cacheDefaults struct {
+	// the per-arch registry reference used for the cache
+	registryRef string
+	// the image being built, used as the cache source for inline caches
+	imageRef string
+	// the per-arch directory used for local caches
+	localDir string
+}
+
+// getCacheDefaults derives the per-arch cache locations from the image being built so caches
+// for different architectures do not overwrite each other, the platform is the one the image is built for
+func getCacheDefaults(ref *reference.Reference, isOfficial bool, archOption string, platform string) (*cacheDefaults, error) {
+	arch := platformArch(platform)
+	if arch == "" {
+		return nil, errors.Errorf("the build platform %q has no architecture to name the cache", platform)
+	}
+
+	// a cache ref without the arch would be shared between all the architectures
+	if reference.ArchOption(archOption) != reference.ArchAppend {
+		archOption = string(reference.ArchPrepend)
+	}
+
+	cacheUri, err := reference.NewUri(fmt.Sprintf("%s:%s", ref.ShortName(), cacheTagSuffix), &reference.UriOptions{
+		Registry:   ref.Registry(),
+		Official:   isOfficial,
+		Arch:       arch,
+		ArchOption: reference.ArchOption(archOption),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	localDir := filepath.Join(cacheDir, "tugboat", "buildcache", filepath.FromSlash(ref.ShortName()), arch)
+
+	return &cacheDefaults{
+		registryRef: cacheUri.Remote(),
+		imageRef:    ref.Remote(),
+		localDir:    localDir,
+	}, nil
+}
+
+// resolveCacheSpecs expands the short cache types into full specs understood by docker build
+func resolveCacheSpecs(specs []string, direction cacheDirection, defaults *cacheDefaults) []string {
+	var resolved []string
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		resolved = append(resolved, resolveCacheSpec(spec, direction, defaults))
+	}
+	return resolved
+}
+
+func resolveCacheSpec(spec string, direction cacheDirection, defaults *cacheDefaults) string {
+	switch spec {
+	case cacheTypeRegistry, cacheTypeLocal, cacheTypeInline:
+		spec = "type=" + spec
+	}
+
+	fields := parseCacheSpec(spec)
+
+	cacheType, ok := fields["type"]
+	if !ok {
+		// an image reference to use as a registry cache
+		return spec
+	}
+
+	switch cacheType {
+	case cacheTypeRegistry:
+		if fields["ref"] == "" {
+			spec = appendCacheField(spec, "ref", defaults.registryRef)
+		}
+		if direction == cacheExport && fields["mode"] == "" {
+			spec = appendCacheField(spec, "mode", "max")
+		}
+	case cacheTypeLocal:
+		key := "src"
+		if direction == cacheExport {
+			key = "dest"
+		}
+		if fields[key] == "" {
+			spec = appendCacheField(spec, key, defaults.localDir)
+		}
+	case cacheTypeInline:
+		// the inline cache is stored in the image itself, so it is imported from the image
+		if direction == cacheImport {
+			return fmt.Sprintf("type=%s,ref=%s", cacheTypeRegistry, defaults.imageRef)
+		}
+	}
+
+	return spec
+}
+
+// cacheRefs returns the registry references from the cache specs for use with the Engine API
+func cacheRefs(specs []string) []string {
+	var refs []string
+	for _, spec := range specs {
+		fields := parseCacheSpec(spec)
+		cacheType, ok := fields["type"]
+		if !ok {
+			refs = append(refs, spec)
+			continue
+		}
+		if cacheType == cacheTypeRegistry && fields["ref"] != "" {
+			refs = append(refs, fields["ref"])
+		}
+	}
+	return refs
+}
+
+func parseCacheSpec(spec string) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+func appendCacheField(spec string, key string, value string) string {
+	return fmt.Sprintf("%s,%s=%s", spec, key, value)
+}
+
+// platformArch returns the architecture of a platform (i.e. linux/arm64/v8 -> arm64)
+func platformArch(platform string) string {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/reference"
+)
+
+func Test_getCacheDefaults(t *testing.T) {
+	ref, _ := reference.NewUri("namespace/image:tag", &reference.UriOptions{
+		Registry:   "docker.io",
+		ArchOption: reference.ArchOmit,
+	})
+
+	testCases := []struct {
+		name       string
+		archOption string
+		platform   string
+		expected   string
+	}{
+		{
+			name:       "prepend",
+			archOption: "prepend",
+			platform:   "linux/amd64",
+			expected:   "docker.io/namespace/image:amd64-buildcache",
+		},
+		{
+			name:       "append",
+			archOption: "append",
+			platform:   "linux/arm64/v8",
+			expected:   "docker.io/namespace/image:buildcache-arm64",
+		},
+		{
+			name:       "omit still adds the arch",
+			archOption: "omit",
+			platform:   "linux/arm64",
+			expected:   "docker.io/namespace/image:arm64-buildcache",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defaults, err := getCacheDefaults(ref, false, tc.archOption, tc.platform)
+			if err != nil {
+				t.Fatalf("An unexpected error occurred: %v", err)
+			}
+
+			if tc.expected != defaults.registryRef {
+				t.Errorf("expected %v, got %v", tc.expected, defaults.registryRef)
+			}
+
+			if !strings.HasSuffix(defaults.localDir, platformArch(tc.platform)) {
+				t.Errorf("expected the local cache dir %v to be per-arch", defaults.localDir)
+			}
+		})
+	}
+
+	// the cache is never shared between the architectures
+	if _, err := getCacheDefaults(ref, false, "prepend", ""); err == nil {
+		t.Error("expected an error without a build platform")
+	}
+}
+
+func Test_resolveCacheSpecs(t *testing.T) {
+	defaults := &cacheDefaults{
+		registryRef: "docker.io/namespace/image:amd64-buildcache",
+		imageRef:    "docker.io/namespace/image:tag",
+		localDir:    "/cache/amd64",
+	}
+
+	testCases := []struct {
+		name      string
+		specs     []string
+		direction cacheDirection
+		expected  string
+	}{
+		{
+			name:      "registry import",
+			specs:     []string{"registry"},
+			direction: cacheImport,
+			expected:  "type=registry,ref=docker.io/namespace/image:amd64-buildcache",
+		},
+		{
+			name:      "registry export",
+			specs:     []string{"type=registry"},
+			direction: cacheExport,
+			expected:  "type=registry,ref=docker.io/namespace/image:amd64-buildcache,mode=max",
+		},
+		{
+			name:      "registry with a ref",
+			specs:     []string{"type=registry,ref=other:cache,mode=min"},
+			direction: cacheExport,
+			expected:  "type=registry,ref=other:cache,mode=min",
+		},
+		{
+			name:      "local import",
+			specs:     []string{"local"},
+			direction: cacheImport,
+			expected:  "type=local,src=/cache/amd64",
+		},
+		{
+			name:      "local export",
+			specs:     []string{"local"},
+			direction: cacheExport,
+			expected:  "type=local,dest=/cache/amd64",
+		},
+		{
+			name:      "inline import",
+			specs:     []string{"inline"},
+			direction: cacheImport,
+			expected:  "type=registry,ref=docker.io/namespace/image:tag",
+		},
+		{
+			name:      "inline export",
+			specs:     []string{"inline"},
+			direction: cacheExport,
+			expected:  "type=inline",
+		},
+		{
+			name:      "image reference",
+			specs:     []string{"namespace/image:cache", " "},
+			direction: cacheImport,
+			expected:  "namespace/image:cache",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := strings.Join(resolveCacheSpecs(tc.specs, tc.direction, defaults), " ")
+
+			if tc.expected != actual {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_cacheRefs(t *testing.T) {
+	specs := []string{"type=registry,ref=image:cache", "type=local,src=/cache", "image:latest"}
+
+	expected := "image:cache image:latest"
+	actual := strings.Join(cacheRefs(specs), " ")
+
+	if expected != actual {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os/exec"
 	"strings"
+	"sync"
 	"tugboat/internal/clients/docker"
 	"tugboat/internal/driver"
 	"tugboat/internal/pkg/reference"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,6 +26,9 @@ type DockerDriver struct {
 	ArchitectureTag string
 	client          *client.Client
 	registry        *registry.Registry
+	registryCheck   sync.Once
+	// registryClient reads the digests of the images in the registry to skip unneeded pulls
+	registryClient *registry.Client
 }
 
 // NewDockerDriver creates a new instance of DockerDriver
@@ -40,16 +45,38 @@ func NewDockerDriver(opts driver.DriverOptions) (*DockerDriver, error) {
 		ArchitectureTag: opts.ArchitectureTag,
 		client:          client,
 		registry:        opts.Registry,
+		registryClient:  newRegistryClient(opts.Registry),
 	}, nil
 }
 
 func (d *DockerDriver) BuildImage(ctx context.Context, opts driver.BuildOptions) (io.ReadCloser, error) {
+	d.checkRegistryConfig(ctx)
+
 	buildUris, err := driver.GenerateAllUris(d.registry.ServerAddress, d.registry.Namespace, opts.Tags, d.Official, reference.ArchOption(d.ArchitectureTag))
 	if err != nil {
 		return nil, err
 	}
 
-	return buildImage(ctx, buildUris, d.Official, d.DryRun, d.Debug, d.ArchitectureTag, opts)
+	buildPlatform, err := d.buildPlatform(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildImage(ctx, buildUris, d.Official, d.DryRun, d.Debug, d.ArchitectureTag, buildPlatform, opts)
+}
+
+// buildPlatform returns the platform the image is built for, which is the platform of the docker
+// daemon when none is requested. It is only needed to name the per-arch build caches.
+func (d *DockerDriver) buildPlatform(ctx context.Context, opts driver.BuildOptions) (string, error) {
+	if opts.Platform != "" || (len(opts.CacheFrom) == 0 && len(opts.CacheTo) == 0) {
+		return opts.Platform, nil
+	}
+
+	version, err := d.client.ServerVersion(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "reading the platform of the docker daemon for the build cache, set build.platform instead")
+	}
+	return fmt.Sprintf("%s/%s", version.Os, version.Arch), nil
 }
 
 func (d *DockerDriver) PullImage(ctx context.Context, image string) (io.ReadCloser, error) {
@@ -58,13 +85,19 @@ func (d *DockerDriver) PullImage(ctx context.Context, image string) (io.ReadClos
 		return nil, err
 	}
 
-	log.Infof("Pulling %s", uri.Remote())
-
 	if d.DryRun {
+		log.Infof("Pulling %s", uri.Remote())
 		return nil, nil
 	}
 
-	encodedRegistryAuth, err := encodeRegistryCredentials(d.registry)
+	if d.isImageCurrent(ctx, uri.Remote()) {
+		log.Infof("%s is up to date, skipping the pull", uri.Remote())
+		return nil, nil
+	}
+
+	log.Infof("Pulling %s", uri.Remote())
+
+	encodedRegistryAuth, err := encodeRegistryCredentials(ctx, d.registry)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +106,7 @@ func (d *DockerDriver) PullImage(ctx context.Context, image string) (io.ReadClos
 		RegistryAuth: encodedRegistryAuth,
 	}
 
-	response, err := d.client.ImagePull(ctx, image, pullOpts)
+	response, err := d.imagePull(ctx, image, pullOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -96,13 +129,21 @@ func (d *DockerDriver) PullImageWithArch(ctx context.Context, image string, arch
 }
 
 func (d *DockerDriver) pullImage(ctx context.Context, uri string) (io.ReadCloser, error) {
-	log.Infof("Pulling %s", uri)
+	d.checkRegistryConfig(ctx)
 
 	if d.DryRun {
+		log.Infof("Pulling %s", uri)
 		return nil, nil
 	}
 
-	encodedRegistryAuth, err := encodeRegistryCredentials(d.registry)
+	if d.isImageCurrent(ctx, uri) {
+		log.Infof("%s is up to date, skipping the pull", uri)
+		return nil, nil
+	}
+
+	log.Infof("Pulling %s", uri)
+
+	encodedRegistryAuth, err := encodeRegistryCredentials(ctx, d.registry)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +152,7 @@ func (d *DockerDriver) pullImage(ctx context.Context, uri string) (io.ReadCloser
 		RegistryAuth: encodedRegistryAuth,
 	}
 
-	response, err := d.client.ImagePull(ctx, uri, pullOpts)
+	response, err := d.imagePull(ctx, uri, pullOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +172,7 @@ func (d *DockerDriver) PushImage(ctx context.Context, image string) (io.ReadClos
 		return nil, nil
 	}
 
-	encodedRegistryAuth, err := encodeRegistryCredentials(d.registry)
+	encodedRegistryAuth, err := encodeRegistryCredentials(ctx, d.registry)
 	if err != nil {
 		return nil, err
 	}
@@ -163,13 +204,15 @@ func (d *DockerDriver) PushImageWithArch(ctx context.Context, image string, arch
 }
 
 func (d *DockerDriver) pushImage(ctx context.Context, uri string) (io.ReadCloser, error) {
+	d.checkRegistryConfig(ctx)
+
 	log.Infof("Pushing %s", uri)
 
 	if d.DryRun {
 		return nil, nil
 	}
 
-	encodedRegistryAuth, err := encodeRegistryCredentials(d.registry)
+	encodedRegistryAuth, err := encodeRegistryCredentials(ctx, d.registry)
 	if err != nil {
 		return nil, err
 	}
@@ -256,7 +299,7 @@ func (d *DockerDriver) CreateManifest(ctx context.Context, opts driver.ManifestC
 
 		// Create the manifest
 		if err := createManifest(
-			ctx, manifestTagUri, opts.SupportedArchitectures, d.Official, d.ArchitectureTag, d.DryRun, d.Debug,
+			ctx, manifestTagUri, opts.SupportedArchitectures, d.Official, d.ArchitectureTag, d.registry.TLS.Insecure, d.DryRun, d.Debug,
 		); err != nil {
 			return nil, err
 		}
@@ -293,6 +336,7 @@ func (d *DockerDriver) PushManifest(ctx context.Context, manifestList string, op
 	}
 
 	// Push the manifest to the registry
+	opts.Insecure = d.registry.TLS.Insecure
 	if err := pushManifest(ctx, manifestUri, d.DryRun, d.Debug, opts); err != nil {
 		log.Errorf("pushing the manifest '%s' failed: %v", manifestUri.Remote(), err)
 	}
@@ -347,15 +391,32 @@ func (d *DockerDriver) GetUriWithArch(tag string, arch string) (*reference.Refer
 }
 
 func (d *DockerDriver) login(ctx context.Context) error {
-	log.Infof("Logging into %v as %v", d.registry.ServerAddress, d.registry.User.Name)
+	d.checkRegistryConfig(ctx)
+
+	if d.registry.DockerCredentials {
+		// the existing docker login session is used
+		return nil
+	}
+
+	// short-lived credentials are renewed before each login
+	user, err := d.registry.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Logging into %v as %v", d.registry.ServerAddress, user.Name)
 
 	if d.DryRun {
 		return nil
 	}
 
-	loginCmd := []string{"login", "--username", d.registry.User.Name, "--password", d.registry.User.Password, d.registry.ServerAddress}
+	// the password is provided through stdin so it is not exposed in the process list
+	loginCmd := []string{"login", "--username", user.Name, "--password-stdin", d.registry.ServerAddress}
 
-	output, err := exec.Command("docker", loginCmd...).Output()
+	cmd := exec.Command("docker", loginCmd...)
+	cmd.Stdin = strings.NewReader(user.Password)
+
+	output, err := cmd.Output()
 	if err != nil {
 		log.Errorf("Docker login failed: %v", err)
 		return ErrDockerLogin
@@ -366,6 +427,11 @@ func (d *DockerDriver) login(ctx context.Context) error {
 }
 
 func (d *DockerDriver) logout(ctx context.Context) error {
+	if d.registry.DockerCredentials {
+		// keep the existing docker login session
+		return nil
+	}
+
 	log.Infof("Logging out of %v", d.registry.ServerAddress)
 
 	if d.DryRun {
@@ -19,6 +19,7 @@ import (
 	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -27,15 +28,15 @@ var ErrDockerLogin = errors.New("docker login error")
 var ErrDockerLogout = errors.New("docker logout error")
 var ErrCommandFailure = errors.New("command execution failed")
 
-func buildImage(ctx context.Context, references []*reference.Reference, isOfficial bool, isDryRun bool, isDebug bool, archOption string, opts driver.BuildOptions) (io.ReadCloser, error) {
-	arguments, err := getBuildArgs(references, isOfficial, archOption, opts)
+func buildImage(ctx context.Context, references []*reference.Reference, isOfficial bool, isDryRun bool, isDebug bool, archOption string, buildPlatform string, opts driver.BuildOptions) (io.ReadCloser, error) {
+	arguments, err := getBuildArgs(references, isOfficial, archOption, buildPlatform, opts)
 	if err != nil {
 		return nil, err
 	}
 	cmd := getCommand(arguments)
 
 	log.Infof("Building %s using %s/%s", references[0].Remote(), opts.Context, opts.Dockerfile)
-	log.Debugf("command: %v", cmd)
+	log.Debugf("command: %v %v", cmd.Command, redactArgs(cmd.Args))
 
 	if isDryRun {
 		return nil, nil
@@ -50,7 +51,7 @@ func buildImage(ctx context.Context, references []*reference.Reference, isOffici
 	return outputStream, nil
 }
 
-func getBuildArgs(refs []*reference.Reference, isOfficial bool, archOption string, opts driver.BuildOptions) ([]string, error) {
+func getBuildArgs(refs []*reference.Reference, isOfficial bool, archOption string, buildPlatform string, opts driver.BuildOptions) ([]string, error) {
 	args := []string{"build"}
 
 	for _, tag := range refs {
@@ -71,15 +72,66 @@ func getBuildArgs(refs []*reference.Reference, isOfficial bool, archOption strin
 		args = append(args, "--pull")
 	}
 
+	if opts.Target != "" {
+		args = append(args, "--target", opts.Target)
+	}
+
+	for _, label := range opts.Labels {
+		args = append(args, "--label", label)
+	}
+
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+
+	if opts.Network != "" {
+		args = append(args, "--network", opts.Network)
+	}
+
+	for _, host := range opts.AddHosts {
+		args = append(args, "--add-host", host)
+	}
+
+	if opts.ShmSize != "" {
+		args = append(args, "--shm-size", opts.ShmSize)
+	}
+
+	for _, ulimit := range opts.Ulimits {
+		args = append(args, "--ulimit", ulimit)
+	}
+
+	for _, ssh := range opts.Ssh {
+		args = append(args, "--ssh", ssh)
+	}
+
+	for _, secret := range opts.Secrets {
+		args = append(args, "--secret", secret)
+	}
+
+	if len(opts.CacheFrom) > 0 || len(opts.CacheTo) > 0 {
+		defaults, err := getCacheDefaults(refs[0], isOfficial, archOption, buildPlatform)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cacheFrom := range resolveCacheSpecs(opts.CacheFrom, cacheImport, defaults) {
+			args = append(args, "--cache-from", cacheFrom)
+		}
+
+		for _, cacheTo := range resolveCacheSpecs(opts.CacheTo, cacheExport, defaults) {
+			args = append(args, "--cache-to", cacheTo)
+		}
+	}
+
 	args = append(args, opts.Context)
 
 	return args, nil
 }
 
-func createManifest(ctx context.Context, reference *reference.Reference, supportedArchitectures []string, isOfficial bool, archOption string, isDryRun bool, isDebug bool) error {
+func createManifest(ctx context.Context, reference *reference.Reference, supportedArchitectures []string, isOfficial bool, archOption string, isInsecure bool, isDryRun bool, isDebug bool) error {
 	log.Infof("Creating Manifest for %v", reference.Remote())
 
-	arguments, err := getCreateArgs(reference, supportedArchitectures, isOfficial, archOption)
+	arguments, err := getCreateArgs(reference, supportedArchitectures, isOfficial, archOption, isInsecure)
 	if err != nil {
 		return err
 	}
@@ -92,8 +144,14 @@ func createManifest(ctx context.Context, reference *reference.Reference, support
 	return nil
 }
 
-func getCreateArgs(ref *reference.Reference, supportedArchitectures []string, isOfficial bool, archOption string) ([]string, error) {
-	args := []string{"manifest", "create", ref.Remote()}
+func getCreateArgs(ref *reference.Reference, supportedArchitectures []string, isOfficial bool, archOption string, isInsecure bool) ([]string, error) {
+	args := []string{"manifest", "create"}
+
+	if isInsecure {
+		args = append(args, "--insecure")
+	}
+
+	args = append(args, ref.Remote())
 
 	for _, arch := range supportedArchitectures {
 		// Generate the arch uri for the image
@@ -182,6 +240,10 @@ func getPushArgs(reference *reference.Reference, opts driver.ManifestPushOptions
 		args = append(args, "--purge")
 	}
 
+	if opts.Insecure {
+		args = append(args, "--insecure")
+	}
+
 	args = append(args, reference.Remote())
 
 	return args, nil
@@ -242,7 +304,9 @@ func packageBuildContext(context string, dockerfile string) (io.ReadCloser, erro
 	return buildContext, nil
 }
 
-func imageBuildOptions(buildUris []*reference.Reference, opts driver.BuildOptions) types.ImageBuildOptions {
+// imageBuildOptions converts the build options for use with the Engine API. The ssh and secret options
+// require a BuildKit session and are only supported when building with the docker cli.
+func imageBuildOptions(buildUris []*reference.Reference, isOfficial bool, archOption string, buildPlatform string, opts driver.BuildOptions) (types.ImageBuildOptions, error) {
 	// Prepare the tags
 	var buildTags []string
 	for _, uri := range buildUris {
@@ -258,21 +322,69 @@ func imageBuildOptions(buildUris []*reference.Reference, opts driver.BuildOption
 		buildArgs[kv[0]] = &kv[1]
 	}
 
-	return types.ImageBuildOptions{
-		Dockerfile: opts.Dockerfile,
-		Tags:       buildTags,
-		NoCache:    opts.NoCache,
-		Remove:     opts.Rm,
-		PullParent: opts.Pull,
-		BuildArgs:  buildArgs,
+	// Prepare the labels
+	labels := make(map[string]string)
+	for _, pair := range opts.Labels {
+		key, value, _ := strings.Cut(pair, "=")
+		labels[key] = value
+	}
+
+	var shmSize int64
+	if opts.ShmSize != "" {
+		size, err := units.RAMInBytes(opts.ShmSize)
+		if err != nil {
+			return types.ImageBuildOptions{}, errors.Wrap(err, "invalid shm-size")
+		}
+		shmSize = size
 	}
+
+	var ulimits []*units.Ulimit
+	for _, value := range opts.Ulimits {
+		ulimit, err := units.ParseUlimit(value)
+		if err != nil {
+			return types.ImageBuildOptions{}, errors.Wrap(err, "invalid ulimit")
+		}
+		ulimits = append(ulimits, ulimit)
+	}
+
+	// Prepare the cache sources, only registry caches can be used with the Engine API
+	var cacheFrom []string
+	if len(opts.CacheFrom) > 0 {
+		defaults, err := getCacheDefaults(buildUris[0], isOfficial, archOption, buildPlatform)
+		if err != nil {
+			return types.ImageBuildOptions{}, err
+		}
+		cacheFrom = cacheRefs(resolveCacheSpecs(opts.CacheFrom, cacheImport, defaults))
+	}
+
+	return types.ImageBuildOptions{
+		Dockerfile:  opts.Dockerfile,
+		Tags:        buildTags,
+		NoCache:     opts.NoCache,
+		Remove:      opts.Rm,
+		PullParent:  opts.Pull,
+		BuildArgs:   buildArgs,
+		Target:      opts.Target,
+		Labels:      labels,
+		Platform:    opts.Platform,
+		NetworkMode: opts.Network,
+		ExtraHosts:  opts.AddHosts,
+		ShmSize:     shmSize,
+		Ulimits:     ulimits,
+		CacheFrom:   cacheFrom,
+	}, nil
 }
 
 // Returns base64 encoded registry credentials
-func encodeRegistryCredentials(registry *registry.Registry) (string, error) {
+func encodeRegistryCredentials(ctx context.Context, registry *registry.Registry) (string, error) {
+	user, err := registry.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	authConfig := registrytypes.AuthConfig{
-		Username:      registry.User.Name,
-		Password:      registry.User.Password,
+		Username:      user.Name,
+		Password:      user.Password,
 		ServerAddress: registry.ServerAddress,
 	}
 	authConfigAsBytes, err := json.Marshal(authConfig)
@@ -291,6 +403,18 @@ func getCommand(args []string) *term.Command {
 	}
 }
 
+// redactArgs returns a copy of the command arguments with the value of each secret redacted
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted)-1; i++ {
+		if redacted[i] == "--secret" {
+			redacted[i+1] = "[REDACTED]"
+		}
+	}
+	return redacted
+}
+
 func executeCommand(cmd *term.Command, isDryRun bool, isDebug bool) error {
 
 	if err := validateCommand(cmd); err != nil {
@@ -104,12 +104,20 @@ func Test_getCreateArgs(t *testing.T) {
 	})
 
 	expectedArgs := "manifest create docker.io/namespace/image:tag docker.io/namespace/image:arm64-tag"
-	args, _ := getCreateArgs(ref, basicCreateOpts.SupportedArchitectures, false, "prepend")
+	args, _ := getCreateArgs(ref, basicCreateOpts.SupportedArchitectures, false, "prepend", false)
 	actualArgs := strings.Join(args, " ")
 
 	if actualArgs != expectedArgs {
 		t.Errorf("expected commands %v, got %v", expectedArgs, actualArgs)
 	}
+
+	expectedArgs = "manifest create --insecure docker.io/namespace/image:tag docker.io/namespace/image:arm64-tag"
+	args, _ = getCreateArgs(ref, basicCreateOpts.SupportedArchitectures, false, "prepend", true)
+	actualArgs = strings.Join(args, " ")
+
+	if actualArgs != expectedArgs {
+		t.Errorf("expected commands %v, got %v", expectedArgs, actualArgs)
+	}
 }
 
 func Test_getPushArgs(t *testing.T) {
@@ -131,6 +139,14 @@ func Test_getPushArgs(t *testing.T) {
 	if actualArgs != expectedArgs {
 		t.Errorf("expected commands %v, got %v", expectedArgs, actualArgs)
 	}
+
+	expectedArgs = "manifest push --purge --insecure docker.io/namespace/image:tag"
+	args, _ = getPushArgs(ref, driver.ManifestPushOptions{Purge: true, Insecure: true})
+	actualArgs = strings.Join(args, " ")
+
+	if actualArgs != expectedArgs {
+		t.Errorf("expected commands %v, got %v", expectedArgs, actualArgs)
+	}
 }
 
 func Test_getAnnotateCommands(t *testing.T) {
@@ -174,3 +190,49 @@ func Test_getRmArgs(t *testing.T) {
 		t.Errorf("expected args %v, got %v", expectedArgs, actualArgs)
 	}
 }
+
+func Test_getBuildArgs(t *testing.T) {
+	ref, _ := reference.NewUri("namespace/image:tag", &reference.UriOptions{
+		Registry:   "docker.io",
+		ArchOption: reference.ArchOmit,
+	})
+
+	opts := driver.BuildOptions{
+		Context:    ".",
+		Dockerfile: "Dockerfile",
+		Target:     "release",
+		Labels:     []string{"foo=bar"},
+		Platform:   "linux/arm64",
+		Network:    "host",
+		AddHosts:   []string{"host:127.0.0.1"},
+		ShmSize:    "64m",
+		Ulimits:    []string{"nofile=1024:2048"},
+		Ssh:        []string{"default"},
+		Secrets:    []string{"id=mysecret,src=/local/secret"},
+	}
+
+	expectedArgs := "build -t docker.io/namespace/image:tag -f ./Dockerfile --target release --label foo=bar " +
+		"--platform linux/arm64 --network host --add-host host:127.0.0.1 --shm-size 64m " +
+		"--ulimit nofile=1024:2048 --ssh default --secret id=mysecret,src=/local/secret ."
+	args, _ := getBuildArgs([]*reference.Reference{ref}, false, "omit", opts.Platform, opts)
+	actualArgs := strings.Join(args, " ")
+
+	if actualArgs != expectedArgs {
+		t.Errorf("expected args %v, got %v", expectedArgs, actualArgs)
+	}
+}
+
+func Test_redactArgs(t *testing.T) {
+	args := []string{"build", "--secret", "id=mysecret,src=/local/secret", "."}
+
+	expectedArgs := "build --secret [REDACTED] ."
+	actualArgs := strings.Join(redactArgs(args), " ")
+
+	if actualArgs != expectedArgs {
+		t.Errorf("expected args %v, got %v", expectedArgs, actualArgs)
+	}
+
+	if args[2] != "id=mysecret,src=/local/secret" {
+		t.Errorf("expected the original args to be unchanged, got %v", args)
+	}
+}
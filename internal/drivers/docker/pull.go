@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+	"tugboat/internal/pkg/reference/distribution/reference"
+	"tugboat/internal/registry"
+
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
+	log "github.com/sirupsen/logrus"
+)
+
+// rateLimitRetryDelay is waited between pulls rejected by the rate limit of the registry
+const rateLimitRetryDelay = time.Minute
+
+// isImageCurrent returns true when the local image has the digest the tag has in the registry, so
+// the pull can be skipped. The digest is read with a HEAD request, which does not count as a pull
+// in the Docker Hub rate limit, before the local image is inspected so the rate limit is also
+// reported for the images that were never pulled.
+func (d *DockerDriver) isImageCurrent(ctx context.Context, uri string) bool {
+	if d.registryClient == nil {
+		return false
+	}
+
+	named, err := reference.ParseNormalizedNamed(uri)
+	if err != nil {
+		return false
+	}
+
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	if !ok || !sameRegistry(reference.Domain(named), d.registryClient.Host()) {
+		return false
+	}
+
+	remote, err := d.registryClient.HeadManifest(ctx, reference.Path(named), tagged.Tag())
+	if err != nil {
+		log.Debugf("Unable to read the digest of %s: %v", uri, err)
+		return false
+	}
+
+	local, _, err := d.client.ImageInspectWithRaw(ctx, uri)
+	if err != nil {
+		return false
+	}
+
+	return hasRepoDigest(local.RepoDigests, remote.Digest)
+}
+
+// imagePull pulls the image, retrying while the pull is rate limited and the total wait is below
+// the configured maximum
+func (d *DockerDriver) imagePull(ctx context.Context, uri string, opts types.ImagePullOptions) (io.ReadCloser, error) {
+	var waited time.Duration
+	for {
+		response, err := d.client.ImagePull(ctx, uri, opts)
+		if err == nil || !isRateLimitError(err) || waited >= d.registry.RateLimitWait {
+			return response, err
+		}
+
+		delay := min(rateLimitRetryDelay, d.registry.RateLimitWait-waited)
+		log.Warnf("The rate limit was reached pulling %s, retrying in %s", uri, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		waited += delay
+	}
+}
+
+// hasRepoDigest returns true if one of the repository digests of a local image is the digest
+func hasRepoDigest(repoDigests []string, dgst digest.Digest) bool {
+	for _, repoDigest := range repoDigests {
+		if strings.HasSuffix(repoDigest, "@"+dgst.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRateLimitError returns true when the docker daemon failed a pull on the registry rate limit
+func isRateLimitError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "toomanyrequests") || strings.Contains(message, "429 too many requests")
+}
+
+// sameRegistry compares the domain of a reference with the host of a registry client
+func sameRegistry(domain string, host string) bool {
+	normalize := func(host string) string {
+		switch host {
+		case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+			return "docker.io"
+		}
+		return host
+	}
+	return normalize(domain) == normalize(host)
+}
+
+// newRegistryClient returns the client used to read the digests of the registry images
+func newRegistryClient(r *registry.Registry) *registry.Client {
+	if r == nil {
+		return nil
+	}
+	return r.Client()
+}
@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"tugboat/internal/registry"
+	"tugboat/internal/registry/registrytest"
+
+	"github.com/docker/docker/client"
+	"github.com/opencontainers/go-digest"
+	log "github.com/sirupsen/logrus"
+)
+
+func Test_hasRepoDigest(t *testing.T) {
+	dgst := digest.Digest("sha256:4b825dc642cb6eb9a060e54bf8d69288fbee4904a7e6d1e0e5e1d2c1a3b4c5d6")
+
+	testCases := []struct {
+		name        string
+		repoDigests []string
+		expected    bool
+	}{
+		{name: "matching digest", repoDigests: []string{"tugboat/app@" + dgst.String()}, expected: true},
+		{name: "other digest", repoDigests: []string{"tugboat/app@sha256:0000"}, expected: false},
+		{name: "never pulled", repoDigests: nil, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := hasRepoDigest(tc.repoDigests, dgst); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_isRateLimitError(t *testing.T) {
+	testCases := map[string]bool{
+		"toomanyrequests: You have reached your pull rate limit":   true,
+		"Error response from daemon: 429 Too Many Requests":        true,
+		"Error response from daemon: manifest unknown":             false,
+		"Error response from daemon: pull access denied for image": false,
+	}
+	for message, expected := range testCases {
+		if actual := isRateLimitError(errors.New(message)); actual != expected {
+			t.Errorf("%v: expected %v, got %v", message, expected, actual)
+		}
+	}
+}
+
+func Test_sameRegistry(t *testing.T) {
+	testCases := []struct {
+		domain   string
+		host     string
+		expected bool
+	}{
+		{domain: "docker.io", host: "docker.io", expected: true},
+		{domain: "docker.io", host: "index.docker.io", expected: true},
+		{domain: "ghcr.io", host: "docker.io", expected: false},
+		{domain: "localhost:5000", host: "localhost:5000", expected: true},
+	}
+	for _, tc := range testCases {
+		if actual := sameRegistry(tc.domain, tc.host); actual != tc.expected {
+			t.Errorf("%v %v: expected %v, got %v", tc.domain, tc.host, tc.expected, actual)
+		}
+	}
+}
+
+func TestDockerDriver_isImageCurrent_noLocalImage(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	fake := registrytest.New(t, "", nil)
+	fake.PutImage("ns/app", "1.0.0", "amd64")
+	fake.Header = http.Header{
+		"Ratelimit-Limit":     {"100;w=21600"},
+		"Ratelimit-Remaining": {"5;w=21600"},
+	}
+
+	// the daemon has no image
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"No such image"}`))
+	}))
+	t.Cleanup(daemon.Close)
+
+	daemonClient, err := client.NewClientWithOpts(client.WithHost("tcp://"+daemon.Listener.Addr().String()), client.WithVersion("1.43"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DockerDriver{
+		client:         daemonClient,
+		registryClient: registry.NewClient(fake.Host(), &registry.ClientOptions{HttpClient: fake.Server.Client()}),
+	}
+
+	if d.isImageCurrent(context.Background(), fake.Host()+"/ns/app:1.0.0") {
+		t.Error("expected the image not to be current")
+	}
+
+	// the rate limit is read even though the image was never pulled
+	if count := fake.CountRequests("HEAD /v2/ns/app/manifests/1.0.0"); count != 1 {
+		t.Errorf("expected the manifest to be read once, got %v", count)
+	}
+	if rateLimit := d.registryClient.RateLimit(); rateLimit == nil || rateLimit.Remaining != 5 {
+		t.Errorf("expected 5 remaining pulls, got %+v", rateLimit)
+	}
+	if !strings.Contains(out.String(), "5 of 100 pulls remain") {
+		t.Errorf("expected the low rate limit to be logged, got %v", out.String())
+	}
+}
@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+
+	registrytypes "github.com/docker/docker/api/types/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// dockerCertsDir is where the docker daemon reads the certificates of each registry from
+const dockerCertsDir = "/etc/docker/certs.d"
+
+// checkRegistryConfig warns when the docker daemon is not configured for the tls options of the
+// registry. Images are pushed and pulled by the daemon, which only uses its own configuration.
+func (d *DockerDriver) checkRegistryConfig(ctx context.Context) {
+	d.registryCheck.Do(func() {
+		if d.DryRun || d.registry == nil {
+			return
+		}
+
+		host := d.registry.ServerAddress
+
+		if d.registry.TLS.Insecure {
+			info, err := d.client.Info(ctx)
+			if err != nil {
+				log.Debugf("Unable to read the docker daemon registry configuration: %v", err)
+			} else if !isInsecureRegistry(info.RegistryConfig, host) {
+				log.Warnf("The docker daemon does not treat %s as an insecure registry, add it to the insecure-registries of the daemon configuration", host)
+			}
+		}
+
+		certificates := []struct {
+			file string
+			name string
+		}{
+			{file: d.registry.TLS.CaFile, name: "ca.crt"},
+			{file: d.registry.TLS.ClientCert, name: "client.cert"},
+			{file: d.registry.TLS.ClientKey, name: "client.key"},
+		}
+		for _, certificate := range certificates {
+			if certificate.file == "" {
+				continue
+			}
+
+			path := filepath.Join(dockerCertsDir, host, certificate.name)
+			if _, err := os.Stat(path); err != nil {
+				log.Warnf("The docker daemon reads the certificates of %s from %s, copy %s to %s", host, filepath.Dir(path), certificate.file, path)
+			}
+		}
+	})
+}
+
+// isInsecureRegistry returns true if the docker daemon allows insecure connections to the registry
+func isInsecureRegistry(config *registrytypes.ServiceConfig, host string) bool {
+	if config == nil {
+		return false
+	}
+
+	if index, ok := config.IndexConfigs[host]; ok {
+		return !index.Secure
+	}
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "localhost" {
+		hostname = "127.0.0.1"
+	}
+
+	ip := net.ParseIP(hostname)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range config.InsecureRegistryCIDRs {
+		if (*net.IPNet)(cidr).Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
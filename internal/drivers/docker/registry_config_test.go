@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"net"
+	"testing"
+
+	registrytypes "github.com/docker/docker/api/types/registry"
+)
+
+func Test_isInsecureRegistry(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	config := &registrytypes.ServiceConfig{
+		InsecureRegistryCIDRs: []*registrytypes.NetIPNet{(*registrytypes.NetIPNet)(loopback)},
+		IndexConfigs: map[string]*registrytypes.IndexInfo{
+			"docker.io":                 {Name: "docker.io", Secure: true},
+			"registry.example.com":      {Name: "registry.example.com", Secure: false},
+			"registry.example.com:5000": {Name: "registry.example.com:5000", Secure: true},
+		},
+	}
+
+	testCases := []struct {
+		host     string
+		expected bool
+	}{
+		{host: "docker.io", expected: false},
+		{host: "registry.example.com", expected: true},
+		{host: "registry.example.com:5000", expected: false},
+		{host: "localhost:5000", expected: true},
+		{host: "127.0.0.1:5000", expected: true},
+		{host: "10.0.0.1:5000", expected: false},
+		{host: "ghcr.io", expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			if actual := isInsecureRegistry(config, tc.host); tc.expected != actual {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if isInsecureRegistry(nil, "localhost:5000") {
+		t.Error("expected a registry to be secure without a daemon configuration")
+	}
+}
@@ -14,6 +14,17 @@ type BuildOptions struct {
 	Push       bool
 	Pull       bool
 	NoCache    bool
+	Target     string
+	Labels     []string
+	Platform   string
+	Network    string
+	AddHosts   []string
+	ShmSize    string
+	Ulimits    []string
+	Ssh        []string
+	Secrets    []string
+	CacheFrom  []string
+	CacheTo    []string
 }
 
 func Build(ctx context.Context, d driver.ImageBuilderPusher, opts BuildOptions) error {
@@ -26,6 +37,17 @@ func Build(ctx context.Context, d driver.ImageBuilderPusher, opts BuildOptions)
 		Pull:       opts.Pull,
 		NoCache:    opts.NoCache,
 		Push:       opts.Push,
+		Target:     opts.Target,
+		Labels:     opts.Labels,
+		Platform:   opts.Platform,
+		Network:    opts.Network,
+		AddHosts:   opts.AddHosts,
+		ShmSize:    opts.ShmSize,
+		Ulimits:    opts.Ulimits,
+		Ssh:        opts.Ssh,
+		Secrets:    opts.Secrets,
+		CacheFrom:  opts.CacheFrom,
+		CacheTo:    opts.CacheTo,
 	}
 	output, err := d.BuildImage(ctx, buildOpts)
 	if err != nil {
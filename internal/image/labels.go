@@ -0,0 +1,103 @@
+package image
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The standard annotation keys defined by the OCI image spec
+const (
+	LabelCreated  = "org.opencontainers.image.created"
+	LabelSource   = "org.opencontainers.image.source"
+	LabelVersion  = "org.opencontainers.image.version"
+	LabelRevision = "org.opencontainers.image.revision"
+	LabelRefName  = "org.opencontainers.image.ref.name"
+	LabelTitle    = "org.opencontainers.image.title"
+)
+
+const ociLabelPrefix = "org.opencontainers.image."
+
+type OciLabelOptions struct {
+	Created  time.Time
+	Source   string
+	Version  string
+	Revision string
+	RefName  string
+	Title    string
+	// Labels to leave out, either the full key or the name without the
+	// org.opencontainers.image prefix (i.e. created)
+	Exclude []string
+}
+
+// OciLabels returns the standard OCI labels in 'key=value' format. Labels without a
+// value or that have been excluded are not returned.
+func OciLabels(opts OciLabelOptions) []string {
+	var created string
+	if !opts.Created.IsZero() {
+		created = opts.Created.UTC().Format(time.RFC3339)
+	}
+
+	candidates := []struct {
+		key   string
+		value string
+	}{
+		{LabelCreated, created},
+		{LabelSource, opts.Source},
+		{LabelVersion, opts.Version},
+		{LabelRevision, opts.Revision},
+		{LabelRefName, opts.RefName},
+		{LabelTitle, opts.Title},
+	}
+
+	var labels []string
+	for _, c := range candidates {
+		if c.value == "" || isExcluded(c.key, opts.Exclude) {
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%s=%s", c.key, c.value))
+	}
+
+	return labels
+}
+
+// MergeLabels combines the generated labels with the user defined labels. A user
+// defined label takes precedence over a generated label with the same key.
+func MergeLabels(generated []string, user []string) []string {
+	userKeys := []string{}
+	for _, label := range user {
+		key, _, _ := strings.Cut(label, "=")
+		userKeys = append(userKeys, key)
+	}
+
+	var labels []string
+	for _, label := range generated {
+		key, _, _ := strings.Cut(label, "=")
+		if slices.Contains(userKeys, key) {
+			continue
+		}
+		labels = append(labels, label)
+	}
+
+	return append(labels, user...)
+}
+
+// RefName returns the tag portion of an image reference (i.e. image:1.0.0 -> 1.0.0)
+func RefName(image string) string {
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+func isExcluded(key string, exclude []string) bool {
+	for _, e := range exclude {
+		e = strings.TrimSpace(e)
+		if e == key || ociLabelPrefix+e == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package image
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOciLabels(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		opts     OciLabelOptions
+		expected string
+	}{
+		{
+			name: "all labels",
+			opts: OciLabelOptions{
+				Created:  created,
+				Source:   "https://github.com/gotugboat/tugboat",
+				Version:  "1.0.0",
+				Revision: "abc123",
+				RefName:  "1.0.0",
+				Title:    "tugboat",
+			},
+			expected: "org.opencontainers.image.created=2024-01-02T03:04:05Z " +
+				"org.opencontainers.image.source=https://github.com/gotugboat/tugboat " +
+				"org.opencontainers.image.version=1.0.0 " +
+				"org.opencontainers.image.revision=abc123 " +
+				"org.opencontainers.image.ref.name=1.0.0 " +
+				"org.opencontainers.image.title=tugboat",
+		},
+		{
+			name: "empty values are skipped",
+			opts: OciLabelOptions{
+				Revision: "abc123",
+			},
+			expected: "org.opencontainers.image.revision=abc123",
+		},
+		{
+			name: "excluded by short and full name",
+			opts: OciLabelOptions{
+				Created:  created,
+				Revision: "abc123",
+				Title:    "tugboat",
+				Exclude:  []string{"created", "org.opencontainers.image.title"},
+			},
+			expected: "org.opencontainers.image.revision=abc123",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := strings.Join(OciLabels(tc.opts), " ")
+
+			if tc.expected != actual {
+				t.Errorf("expected '%v', got '%v'", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	generated := []string{"org.opencontainers.image.title=tugboat", "org.opencontainers.image.version=1.0.0"}
+	user := []string{"org.opencontainers.image.title=custom", "foo=bar"}
+
+	expected := "org.opencontainers.image.version=1.0.0 org.opencontainers.image.title=custom foo=bar"
+	actual := strings.Join(MergeLabels(generated, user), " ")
+
+	if expected != actual {
+		t.Errorf("expected '%v', got '%v'", expected, actual)
+	}
+}
+
+func TestRefName(t *testing.T) {
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{image: "image:1.0.0", expected: "1.0.0"},
+		{image: "namespace/image:latest", expected: "latest"},
+		{image: "localhost:5000/image", expected: ""},
+		{image: "image", expected: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			actual := RefName(tc.image)
+
+			if tc.expected != actual {
+				t.Errorf("expected '%v', got '%v'", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package image
+
+import (
+	"context"
+	"path"
+	"tugboat/internal/driver"
+	"tugboat/internal/pkg/reference"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type MirrorOptions struct {
+	// Should be in 'image[:tag]' format, as they were pushed to the source registry
+	Images                 []string
+	SupportedArchitectures []string
+	// IncludeManifestLists copies the images without an architecture as well when they are manifest lists
+	IncludeManifestLists bool
+	Official             bool
+	ArchitectureTag      string
+	DryRun               bool
+}
+
+// Mirror copies the images pushed to the source registry to each of the target registries. The
+// registries are copied from one to another, so nothing is rebuilt or pulled locally. A failing
+// registry does not stop the others from being published to, the status of each is reported.
+func Mirror(ctx context.Context, source *registry.Registry, targets []*registry.Registry, opts MirrorOptions) error {
+	failed := 0
+	for _, target := range targets {
+		if target.ServerAddress == source.ServerAddress && target.Namespace == source.Namespace {
+			log.Warnf("Skipping %s/%s, it is the primary registry", target.ServerAddress, target.Namespace)
+			continue
+		}
+
+		copied, err := mirrorRegistry(ctx, source, target, opts)
+		if err != nil {
+			failed++
+			log.Errorf("Publishing to %s failed: %v", target.ServerAddress, err)
+			continue
+		}
+
+		log.Infof("Published %d images to %s", copied, target.ServerAddress)
+	}
+
+	if failed > 0 {
+		return errors.Errorf("publishing to %d of %d registries failed", failed, len(targets))
+	}
+
+	return nil
+}
+
+// mirrorRegistry copies the images to a single registry, returning the number of images copied
+func mirrorRegistry(ctx context.Context, source *registry.Registry, target *registry.Registry, opts MirrorOptions) (int, error) {
+	sourceClient := source.Client()
+	targetClient := target.Client()
+
+	copied := 0
+	copyImage := func(sourceUri *reference.Reference, targetUri *reference.Reference) error {
+		log.Infof("Copying %v to %v", sourceUri.Remote(), targetUri.Remote())
+
+		if opts.DryRun {
+			return nil
+		}
+
+		src := registry.ImageLocation{Client: sourceClient, Repository: sourceUri.ShortName(), Reference: sourceUri.Tag()}
+		dst := registry.ImageLocation{Client: targetClient, Repository: targetUri.ShortName(), Reference: targetUri.Tag()}
+		if _, err := registry.CopyImage(ctx, src, dst); err != nil {
+			return errors.Wrapf(err, "copying %v", sourceUri.Remote())
+		}
+
+		copied++
+		return nil
+	}
+
+	for _, image := range opts.Images {
+		if len(opts.SupportedArchitectures) == 0 {
+			sourceUri, targetUri, err := mirrorUris(source, target, image, "", opts.Official, reference.ArchOption(opts.ArchitectureTag))
+			if err != nil {
+				return copied, err
+			}
+
+			if err := copyImage(sourceUri, targetUri); err != nil {
+				return copied, err
+			}
+			continue
+		}
+
+		for _, arch := range opts.SupportedArchitectures {
+			sourceUri, targetUri, err := mirrorUris(source, target, image, arch, opts.Official, reference.ArchOption(opts.ArchitectureTag))
+			if err != nil {
+				return copied, err
+			}
+
+			if err := copyImage(sourceUri, targetUri); err != nil {
+				return copied, err
+			}
+		}
+
+		if !opts.IncludeManifestLists {
+			continue
+		}
+
+		sourceUri, targetUri, err := mirrorUris(source, target, image, "", false, reference.ArchOmit)
+		if err != nil {
+			return copied, err
+		}
+
+		if !opts.DryRun {
+			manifest, err := sourceClient.GetManifest(ctx, sourceUri.ShortName(), sourceUri.Tag())
+			if errors.Is(err, registry.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return copied, err
+			}
+
+			if !manifest.IsIndex() {
+				continue
+			}
+		}
+
+		if err := copyImage(sourceUri, targetUri); err != nil {
+			return copied, err
+		}
+	}
+
+	return copied, nil
+}
+
+// mirrorUris returns the uri of the image in the source registry and the uri it is copied to in the
+// target registry. The image keeps its name and tag but is placed in the namespace of the target.
+func mirrorUris(source *registry.Registry, target *registry.Registry, image string, arch string, official bool, archOption reference.ArchOption) (*reference.Reference, *reference.Reference, error) {
+	namespace := target.Namespace
+	if namespace == "" {
+		namespace = source.Namespace
+	}
+
+	generate := func(serverAddress string, namespace string, image string) (*reference.Reference, error) {
+		if arch == "" {
+			return driver.GenerateUri(serverAddress, namespace, image, official, archOption)
+		}
+		return driver.GenerateUriWithArch(serverAddress, namespace, image, official, archOption, arch)
+	}
+
+	sourceUri, err := generate(source.ServerAddress, source.Namespace, image)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetUri, err := generate(target.ServerAddress, namespace, path.Base(image))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sourceUri, targetUri, nil
+}
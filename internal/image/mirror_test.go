@@ -0,0 +1,85 @@
+package image
+
+import (
+	"strings"
+	"testing"
+	"tugboat/internal/pkg/reference"
+	"tugboat/internal/registry"
+)
+
+func Test_mirrorUris(t *testing.T) {
+	source := &registry.Registry{ServerAddress: "docker.io", Namespace: "tugboat"}
+
+	testCases := []struct {
+		name           string
+		target         *registry.Registry
+		image          string
+		arch           string
+		archOption     reference.ArchOption
+		expectedSource string
+		expectedTarget string
+	}{
+		{
+			name:           "target namespace",
+			target:         &registry.Registry{ServerAddress: "ghcr.io", Namespace: "org"},
+			image:          "app:1.0.0",
+			arch:           "amd64",
+			archOption:     reference.ArchPrepend,
+			expectedSource: "docker.io/tugboat/app:amd64-1.0.0",
+			expectedTarget: "ghcr.io/org/app:amd64-1.0.0",
+		},
+		{
+			name:           "source namespace is used by default",
+			target:         &registry.Registry{ServerAddress: "ghcr.io"},
+			image:          "app:1.0.0",
+			archOption:     reference.ArchOmit,
+			expectedSource: "docker.io/tugboat/app:1.0.0",
+			expectedTarget: "ghcr.io/tugboat/app:1.0.0",
+		},
+		{
+			name:           "namespace of the image is replaced",
+			target:         &registry.Registry{ServerAddress: "harbor.example.com", Namespace: "mirror"},
+			image:          "other/app:1.0.0",
+			arch:           "arm64",
+			archOption:     reference.ArchAppend,
+			expectedSource: "docker.io/other/app:1.0.0-arm64",
+			expectedTarget: "harbor.example.com/mirror/app:1.0.0-arm64",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sourceUri, targetUri, err := mirrorUris(source, tc.target, tc.image, tc.arch, false, tc.archOption)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedSource != sourceUri.Remote() {
+				t.Errorf("expected source '%v', got '%v'", tc.expectedSource, sourceUri.Remote())
+			}
+
+			if tc.expectedTarget != targetUri.Remote() {
+				t.Errorf("expected target '%v', got '%v'", tc.expectedTarget, targetUri.Remote())
+			}
+		})
+	}
+}
+
+func TestTaggedImages(t *testing.T) {
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{image: "app:1.0.0", expected: "app:latest app:stable"},
+		{image: "app", expected: "app:latest app:stable"},
+		{image: "localhost:5000/ns/app", expected: "localhost:5000/ns/app:latest localhost:5000/ns/app:stable"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			actual := strings.Join(TaggedImages(tc.image, []string{"latest", "stable"}), " ")
+
+			if tc.expected != actual {
+				t.Errorf("expected '%v', got '%v'", tc.expected, actual)
+			}
+		})
+	}
+}
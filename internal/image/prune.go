@@ -0,0 +1,310 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"tugboat/internal/pkg/reference/distribution/reference"
+	"tugboat/internal/registry"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+)
+
+type PruneOptions struct {
+	// Repository is the path of the image in the registry (i.e. namespace/image)
+	Repository string
+	// KeepLast is the number of semantic versions kept, zero disables the policy
+	KeepLast int
+	// Keep are regular expressions matching the whole tag of the tags that are never deleted
+	Keep []string
+	// OlderThan deletes the tags of images created before this age, zero disables the policy
+	OlderThan time.Duration
+	// SupportedArchitectures are used to find the version of the architecture tags (i.e. amd64-1.0.0)
+	SupportedArchitectures []string
+	// Apply deletes the tags, otherwise only the plan is returned
+	Apply bool
+}
+
+// PruneTag describes a tag of the repository
+type PruneTag struct {
+	Tag     string
+	Digest  digest.Digest
+	Created time.Time
+	// Manifests are the digests of the images when the tag is a manifest list
+	Manifests []digest.Digest
+}
+
+// PruneDecision is the outcome of the policies for a tag
+type PruneDecision struct {
+	Tag    string
+	Digest digest.Digest
+	Delete bool
+	Reason string
+}
+
+// Prune deletes the tags of the repository according to the retention policies. The decision for
+// every tag is returned, the tags are only deleted when the Apply option is set.
+func Prune(ctx context.Context, c *registry.Client, opts PruneOptions) ([]PruneDecision, error) {
+	names, err := c.ListTags(ctx, opts.Repository)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the tags of %s", opts.Repository)
+	}
+
+	var tags []PruneTag
+	for _, name := range sortTags(c.Host(), opts.Repository, names) {
+		tag, err := getPruneTag(ctx, c, opts.Repository, name, opts.OlderThan > 0)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+
+	decisions, err := PlanPrune(tags, opts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Apply {
+		return decisions, nil
+	}
+
+	deleted := make(map[digest.Digest]bool)
+	for _, decision := range decisions {
+		if !decision.Delete || deleted[decision.Digest] {
+			continue
+		}
+
+		log.Infof("Deleting %s:%s (%s)", opts.Repository, decision.Tag, decision.Digest)
+		if err := c.DeleteManifest(ctx, opts.Repository, decision.Digest); err != nil {
+			return decisions, errors.Wrapf(err, "deleting %s:%s", opts.Repository, decision.Tag)
+		}
+		deleted[decision.Digest] = true
+	}
+
+	return decisions, nil
+}
+
+// PlanPrune decides which tags are deleted. A tag is deleted when it is outside of the last
+// versions kept or is older than the age limit, unless it matches a keep pattern, is one of the last
+// versions kept or is referenced by a manifest list that is kept. Registries delete images by digest, so an image is only deleted
+// when every tag referring to it is deleted.
+func PlanPrune(tags []PruneTag, opts PruneOptions, now time.Time) ([]PruneDecision, error) {
+	var keepPatterns []*regexp.Regexp
+	for _, pattern := range opts.Keep {
+		// the pattern must match the whole tag, so 1.0 does not keep 11.0.3
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid keep pattern %q", pattern)
+		}
+		keepPatterns = append(keepPatterns, re)
+	}
+
+	keptVersions := lastVersions(tags, opts.KeepLast, opts.SupportedArchitectures)
+
+	decisions := make([]PruneDecision, len(tags))
+	for i, tag := range tags {
+		decisions[i] = decide(tag, opts, keepPatterns, keptVersions, now)
+	}
+
+	// images of the manifest lists that are kept are protected
+	protected := make(map[digest.Digest]string)
+	for i, tag := range tags {
+		if decisions[i].Delete {
+			continue
+		}
+		for _, dgst := range tag.Manifests {
+			protected[dgst] = tag.Tag
+		}
+	}
+
+	// an image is kept when any of its tags is kept
+	kept := make(map[digest.Digest]string)
+	for i := range decisions {
+		if list, ok := protected[decisions[i].Digest]; ok && decisions[i].Delete {
+			decisions[i] = keep(tags[i], fmt.Sprintf("referenced by the manifest list %s", list))
+		}
+		if !decisions[i].Delete {
+			kept[decisions[i].Digest] = decisions[i].Tag
+		}
+	}
+
+	for i := range decisions {
+		if other, ok := kept[decisions[i].Digest]; ok && decisions[i].Delete {
+			decisions[i] = keep(tags[i], fmt.Sprintf("the image is also tagged %s", other))
+		}
+	}
+
+	return decisions, nil
+}
+
+func decide(tag PruneTag, opts PruneOptions, keepPatterns []*regexp.Regexp, keptVersions map[string]bool, now time.Time) PruneDecision {
+	for _, re := range keepPatterns {
+		if re.MatchString(tag.Tag) {
+			return keep(tag, fmt.Sprintf("matches the keep pattern %s", re))
+		}
+	}
+
+	// the last versions are kept regardless of their age
+	version := tagVersion(tag.Tag, opts.SupportedArchitectures)
+	if version != "" && keptVersions[version] {
+		return keep(tag, fmt.Sprintf("one of the last %d versions", opts.KeepLast))
+	}
+
+	if opts.OlderThan > 0 && !tag.Created.IsZero() && tag.Created.Before(now.Add(-opts.OlderThan)) {
+		return PruneDecision{Tag: tag.Tag, Digest: tag.Digest, Delete: true, Reason: fmt.Sprintf("created more than %s ago", opts.OlderThan)}
+	}
+
+	if version != "" && opts.KeepLast > 0 {
+		return PruneDecision{Tag: tag.Tag, Digest: tag.Digest, Delete: true, Reason: fmt.Sprintf("not one of the last %d versions", opts.KeepLast)}
+	}
+
+	return keep(tag, "no policy applies")
+}
+
+func keep(tag PruneTag, reason string) PruneDecision {
+	return PruneDecision{Tag: tag.Tag, Digest: tag.Digest, Reason: reason}
+}
+
+// lastVersions returns the highest semantic versions of the tags
+func lastVersions(tags []PruneTag, count int, archs []string) map[string]bool {
+	unique := make(map[string]bool)
+	for _, tag := range tags {
+		if version := tagVersion(tag.Tag, archs); version != "" {
+			unique[version] = true
+		}
+	}
+
+	var versions []string
+	for version := range unique {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i], versions[j]) > 0
+	})
+
+	kept := make(map[string]bool)
+	for i := 0; i < count && i < len(versions); i++ {
+		kept[versions[i]] = true
+	}
+	return kept
+}
+
+// tagVersion returns the canonical semantic version of the tag (i.e. v1.2.0), the architecture
+// is removed from the tags of a single architecture (i.e. amd64-1.2, 1.2-amd64). An empty string
+// is returned when the tag is not a version, a version has at least a major and a minor number so
+// the dates and build numbers (i.e. 20240101, 123) are not versions.
+func tagVersion(tag string, archs []string) string {
+	for _, arch := range archs {
+		if trimmed := strings.TrimPrefix(tag, arch+"-"); trimmed != tag {
+			tag = trimmed
+			break
+		}
+		if trimmed := strings.TrimSuffix(tag, "-"+arch); trimmed != tag {
+			tag = trimmed
+			break
+		}
+	}
+
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+
+	// the shorthand of a major version alone (i.e. v1) is accepted by semver
+	core := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '+' })
+	if len(core) == 0 || !strings.Contains(core[0], ".") {
+		return ""
+	}
+
+	if !semver.IsValid(tag) {
+		return ""
+	}
+	return semver.Canonical(tag)
+}
+
+// sortTags orders the tags using the reference ordering, tags that are not valid references are dropped
+func sortTags(host string, repository string, tags []string) []string {
+	prefix := fmt.Sprintf("%s/%s:", host, repository)
+
+	var references []string
+	for _, tag := range tags {
+		references = append(references, prefix+tag)
+	}
+
+	var sorted []string
+	for _, ref := range reference.Sort(references) {
+		if _, err := reference.ParseAnyReference(ref); err != nil || !strings.HasPrefix(ref, prefix) {
+			log.Debugf("Skipping the invalid tag %s", ref)
+			continue
+		}
+		sorted = append(sorted, strings.TrimPrefix(ref, prefix))
+	}
+	return sorted
+}
+
+// getPruneTag fetches the manifest of the tag, and the creation time of the image when required
+func getPruneTag(ctx context.Context, c *registry.Client, repository string, name string, withCreated bool) (*PruneTag, error) {
+	manifest, err := c.GetManifest(ctx, repository, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching the manifest of %s:%s", repository, name)
+	}
+
+	tag := &PruneTag{Tag: name, Digest: manifest.Digest}
+
+	image := manifest
+	if manifest.IsIndex() {
+		descriptors, err := manifest.Manifests()
+		if err != nil {
+			return nil, err
+		}
+		for _, descriptor := range descriptors {
+			tag.Manifests = append(tag.Manifests, descriptor.Digest)
+		}
+
+		if !withCreated || len(descriptors) == 0 {
+			return tag, nil
+		}
+
+		// the creation time of a manifest list is the one of its first image
+		if image, err = c.GetManifest(ctx, repository, descriptors[0].Digest.String()); err != nil {
+			return nil, errors.Wrapf(err, "fetching the manifest of %s@%s", repository, descriptors[0].Digest)
+		}
+	}
+
+	if withCreated {
+		if tag.Created, err = imageCreated(ctx, c, repository, image); err != nil {
+			return nil, errors.Wrapf(err, "reading the creation time of %s:%s", repository, name)
+		}
+	}
+
+	return tag, nil
+}
+
+// imageCreated reads the creation time from the config of the image
+func imageCreated(ctx context.Context, c *registry.Client, repository string, manifest *registry.Manifest) (time.Time, error) {
+	blobs, err := manifest.Blobs()
+	if err != nil || len(blobs) == 0 {
+		return time.Time{}, err
+	}
+
+	content, _, err := c.GetBlob(ctx, repository, blobs[0].Digest)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer content.Close()
+
+	var config struct {
+		Created time.Time `json:"created"`
+	}
+	if err := json.NewDecoder(content).Decode(&config); err != nil {
+		return time.Time{}, errors.Wrap(err, "parsing the image config")
+	}
+
+	return config.Created, nil
+}
@@ -0,0 +1,191 @@
+package image
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestPlanPrune(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	testCases := []struct {
+		name     string
+		tags     []PruneTag
+		opts     PruneOptions
+		expected []string
+	}{
+		{
+			name: "keep the last versions",
+			tags: []PruneTag{
+				{Tag: "1.0.0", Digest: "sha256:a"},
+				{Tag: "1.1.0", Digest: "sha256:b"},
+				{Tag: "v1.10.0", Digest: "sha256:c"},
+				{Tag: "1.2.0", Digest: "sha256:d"},
+				{Tag: "latest", Digest: "sha256:e"},
+			},
+			opts:     PruneOptions{KeepLast: 2},
+			expected: []string{"1.0.0", "1.1.0"},
+		},
+		{
+			name: "zero keeps every version",
+			tags: []PruneTag{
+				{Tag: "1.0.0", Digest: "sha256:a"},
+				{Tag: "1.1.0", Digest: "sha256:b"},
+			},
+			opts: PruneOptions{KeepLast: 0},
+		},
+		{
+			name: "architecture tags count as their version",
+			tags: []PruneTag{
+				{Tag: "amd64-1.0.0", Digest: "sha256:a"},
+				{Tag: "1.0.0-arm64", Digest: "sha256:b"},
+				{Tag: "amd64-2.0.0", Digest: "sha256:c"},
+				{Tag: "2.0.0-arm64", Digest: "sha256:d"},
+			},
+			opts:     PruneOptions{KeepLast: 1, SupportedArchitectures: []string{"amd64", "arm64"}},
+			expected: []string{"amd64-1.0.0", "1.0.0-arm64"},
+		},
+		{
+			name: "keep patterns protect tags",
+			tags: []PruneTag{
+				{Tag: "1.0.0", Digest: "sha256:a"},
+				{Tag: "1.1.0", Digest: "sha256:b"},
+				{Tag: "2.0.0", Digest: "sha256:c"},
+			},
+			opts:     PruneOptions{KeepLast: 1, Keep: []string{`1\.0\..*`}},
+			expected: []string{"1.1.0"},
+		},
+		{
+			name: "the keep patterns match the whole tag",
+			tags: []PruneTag{
+				{Tag: "1.0", Digest: "sha256:a"},
+				{Tag: "11.0.3", Digest: "sha256:b"},
+				{Tag: "12.0.0", Digest: "sha256:c"},
+			},
+			opts:     PruneOptions{KeepLast: 1, Keep: []string{"1.0"}},
+			expected: []string{"11.0.3"},
+		},
+		{
+			name: "delete the tags older than the age",
+			tags: []PruneTag{
+				{Tag: "nightly-1", Digest: "sha256:a", Created: now.Add(-10 * day)},
+				{Tag: "nightly-2", Digest: "sha256:b", Created: now.Add(-1 * day)},
+				{Tag: "latest", Digest: "sha256:c", Created: now.Add(-30 * day)},
+			},
+			opts:     PruneOptions{OlderThan: 7 * day, Keep: []string{"^latest$"}},
+			expected: []string{"nightly-1"},
+		},
+		{
+			name: "the last versions are kept regardless of their age",
+			tags: []PruneTag{
+				{Tag: "1.0.0", Digest: "sha256:a", Created: now.Add(-90 * day)},
+				{Tag: "1.1.0", Digest: "sha256:b", Created: now.Add(-60 * day)},
+				{Tag: "1.2.0", Digest: "sha256:c", Created: now.Add(-45 * day)},
+				{Tag: "nightly", Digest: "sha256:d", Created: now.Add(-40 * day)},
+				{Tag: "dev", Digest: "sha256:e", Created: now.Add(-1 * day)},
+			},
+			opts:     PruneOptions{KeepLast: 2, OlderThan: 30 * day},
+			expected: []string{"1.0.0", "nightly"},
+		},
+		{
+			name: "a recent tag outside of the last versions is deleted",
+			tags: []PruneTag{
+				{Tag: "1.0.0", Digest: "sha256:a", Created: now.Add(-1 * day)},
+				{Tag: "1.1.0", Digest: "sha256:b", Created: now.Add(-1 * day)},
+			},
+			opts:     PruneOptions{KeepLast: 1, OlderThan: 30 * day},
+			expected: []string{"1.0.0"},
+		},
+		{
+			name: "dates and build numbers are not versions",
+			tags: []PruneTag{
+				{Tag: "20240101", Digest: "sha256:a"},
+				{Tag: "123", Digest: "sha256:b"},
+				{Tag: "1.0.0", Digest: "sha256:c"},
+				{Tag: "1.1.0", Digest: "sha256:d"},
+			},
+			opts:     PruneOptions{KeepLast: 1},
+			expected: []string{"1.0.0"},
+		},
+		{
+			name: "images of a kept manifest list are protected",
+			tags: []PruneTag{
+				{Tag: "amd64-1.0.0", Digest: "sha256:a", Created: now.Add(-30 * day)},
+				{Tag: "arm64-1.0.0", Digest: "sha256:b", Created: now.Add(-30 * day)},
+				{Tag: "stable", Digest: "sha256:c", Created: now.Add(-30 * day), Manifests: []digest.Digest{"sha256:a", "sha256:b"}},
+			},
+			opts: PruneOptions{OlderThan: 7 * day, Keep: []string{"^stable$"}},
+		},
+		{
+			name: "an image is kept when one of its tags is kept",
+			tags: []PruneTag{
+				{Tag: "1.0.0", Digest: "sha256:a"},
+				{Tag: "2.0.0", Digest: "sha256:b"},
+				{Tag: "production", Digest: "sha256:a"},
+			},
+			opts: PruneOptions{KeepLast: 1, Keep: []string{"^production$"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decisions, err := PlanPrune(tc.tags, tc.opts, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var deleted []string
+			for _, decision := range decisions {
+				if decision.Delete {
+					deleted = append(deleted, decision.Tag)
+				}
+			}
+
+			if !reflect.DeepEqual(tc.expected, deleted) {
+				t.Errorf("expected %v to be deleted, got %v", tc.expected, deleted)
+			}
+		})
+	}
+}
+
+func TestPlanPrune_InvalidPattern(t *testing.T) {
+	if _, err := PlanPrune(nil, PruneOptions{Keep: []string{"("}}, time.Now()); err == nil {
+		t.Error("expected an error for an invalid keep pattern")
+	}
+}
+
+func Test_tagVersion(t *testing.T) {
+	archs := []string{"amd64", "arm64"}
+
+	testCases := map[string]string{
+		"1.2.3":        "v1.2.3",
+		"v1.2":         "v1.2.0",
+		"amd64-1.2.3":  "v1.2.3",
+		"1.2.3-arm64":  "v1.2.3",
+		"1.2.3-rc.1":   "v1.2.3-rc.1",
+		"latest":       "",
+		"amd64-latest": "",
+		"20240101":     "",
+		"123":          "",
+		"v1":           "",
+		"1-rc.1":       "",
+	}
+	for tag, expected := range testCases {
+		if actual := tagVersion(tag, archs); actual != expected {
+			t.Errorf("%v: expected '%v', got '%v'", tag, expected, actual)
+		}
+	}
+}
+
+func Test_sortTags(t *testing.T) {
+	tags := []string{"latest", "1.1.0", "1.0.0", "Invalid Tag"}
+
+	expected := []string{"1.0.0", "1.1.0", "latest"}
+	actual := sortTags("docker.io", "tugboat/app", tags)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,109 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"tugboat/internal/driver"
+	"tugboat/internal/pkg/reference"
+	"tugboat/internal/registry"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type RemoteTagOptions struct {
+	// Should be in 'image[:tag]' format
+	SourceImage            string
+	Tags                   []string
+	SupportedArchitectures []string
+	Registry               *registry.Registry
+	Official               bool
+	ArchitectureTag        string
+	DryRun                 bool
+}
+
+// RemoteTag creates the tags in the registry by copying the manifest of the source image under
+// each new tag. The images are never pulled, so no layers are transferred.
+func RemoteTag(ctx context.Context, c *registry.Client, opts RemoteTagOptions) error {
+	if len(opts.Tags) == 0 {
+		return ErrNoProvidedTags
+	}
+
+	// Tag each architecture of the image
+	for _, arch := range opts.SupportedArchitectures {
+		sourceUri, err := driver.GenerateUriWithArch(opts.Registry.ServerAddress, opts.Registry.Namespace, opts.SourceImage, opts.Official, reference.ArchOption(opts.ArchitectureTag), arch)
+		if err != nil {
+			return err
+		}
+
+		for _, targetTag := range opts.Tags {
+			targetImage := fmt.Sprintf("%v:%v", sourceUri.ShortName(), targetTag)
+			targetUri, err := driver.GenerateUriWithArch(opts.Registry.ServerAddress, opts.Registry.Namespace, targetImage, opts.Official, reference.ArchOption(opts.ArchitectureTag), arch)
+			if err != nil {
+				return err
+			}
+
+			if err := retag(ctx, c, sourceUri, targetUri, opts.DryRun); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Tag the image itself when it is a manifest list, or when no architectures are defined
+	sourceUri, err := driver.GenerateUri(opts.Registry.ServerAddress, opts.Registry.Namespace, opts.SourceImage, false, reference.ArchOmit)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.SupportedArchitectures) > 0 {
+		if opts.DryRun {
+			log.Infof("Tagging %v if it is a manifest list", sourceUri.Remote())
+			return nil
+		}
+
+		manifest, err := c.GetManifest(ctx, sourceUri.ShortName(), sourceUri.Tag())
+		if errors.Is(err, registry.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !manifest.IsIndex() {
+			return nil
+		}
+	}
+
+	for _, targetTag := range opts.Tags {
+		targetImage := fmt.Sprintf("%v:%v", sourceUri.ShortName(), targetTag)
+		targetUri, err := driver.GenerateUri(opts.Registry.ServerAddress, opts.Registry.Namespace, targetImage, false, reference.ArchOmit)
+		if err != nil {
+			return err
+		}
+
+		if err := retag(ctx, c, sourceUri, targetUri, opts.DryRun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retag puts the manifest of the source under the tag of the target
+func retag(ctx context.Context, c *registry.Client, sourceUri *reference.Reference, targetUri *reference.Reference, isDryRun bool) error {
+	log.Infof("Tagging %v as %v", sourceUri.Remote(), targetUri.Remote())
+
+	if isDryRun {
+		return nil
+	}
+
+	manifest, err := c.GetManifest(ctx, sourceUri.ShortName(), sourceUri.Tag())
+	if err != nil {
+		return errors.Wrapf(err, "fetching the manifest for %v", sourceUri.Remote())
+	}
+
+	if _, err := c.PutManifest(ctx, targetUri.ShortName(), targetUri.Tag(), manifest); err != nil {
+		return errors.Wrapf(err, "tagging %v", targetUri.Remote())
+	}
+
+	return nil
+}
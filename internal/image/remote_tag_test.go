@@ -0,0 +1,164 @@
+package image
+
+import (
+	"context"
+	"testing"
+	"tugboat/internal/registry"
+	"tugboat/internal/registry/registrytest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// newRemoteTagRegistry starts a registry requiring the user and returns the options tagging app:1.0.0 in it
+func newRemoteTagRegistry(t *testing.T) (*registrytest.Registry, RemoteTagOptions) {
+	fake := registrytest.New(t, "bearer", &registrytest.User{Name: "user", Password: "password"})
+
+	return fake, RemoteTagOptions{
+		SourceImage:     "app:1.0.0",
+		Tags:            []string{"1.0", "latest"},
+		Registry:        &registry.Registry{ServerAddress: fake.Host(), Namespace: "ns"},
+		ArchitectureTag: "append",
+	}
+}
+
+func newRemoteTagClient(fake *registrytest.Registry, user *registry.RegistryUser) *registry.Client {
+	return registry.NewClient(fake.Host(), &registry.ClientOptions{
+		User:       user,
+		HttpClient: fake.Server.Client(),
+	})
+}
+
+// tagDigest returns the digest of the manifest of the tag
+func tagDigest(t *testing.T, c *registry.Client, repository string, tag string) digest.Digest {
+	t.Helper()
+
+	manifest, err := c.GetManifest(context.Background(), repository, tag)
+	if err != nil {
+		t.Fatalf("expected the tag %s to exist, got %v", tag, err)
+	}
+	return manifest.Digest
+}
+
+func TestRemoteTag(t *testing.T) {
+	fake, opts := newRemoteTagRegistry(t)
+	amd64 := fake.PutImage("ns/app", "1.0.0-amd64", "amd64")
+	arm64 := fake.PutImage("ns/app", "1.0.0-arm64", "arm64")
+	opts.SupportedArchitectures = []string{"amd64", "arm64"}
+
+	c := newRemoteTagClient(fake, &registry.RegistryUser{Name: "user", Password: "password"})
+	if err := RemoteTag(context.Background(), c, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range opts.Tags {
+		if actual := tagDigest(t, c, "ns/app", tag+"-amd64"); actual != amd64.Digest {
+			t.Errorf("expected %s-amd64 to be %v, got %v", tag, amd64.Digest, actual)
+		}
+		if actual := tagDigest(t, c, "ns/app", tag+"-arm64"); actual != arm64.Digest {
+			t.Errorf("expected %s-arm64 to be %v, got %v", tag, arm64.Digest, actual)
+		}
+	}
+
+	// the images are never pulled
+	if count := fake.CountRequests("GET /v2/ns/app/blobs/"); count != 0 {
+		t.Errorf("expected no blob to be pulled, got %v requests", count)
+	}
+}
+
+func TestRemoteTag_manifestList(t *testing.T) {
+	fake, opts := newRemoteTagRegistry(t)
+	index := fake.PutIndex("ns/app", "1.0.0", "amd64", "arm64")
+	fake.PutImage("ns/app", "1.0.0-amd64", "amd64")
+	fake.PutImage("ns/app", "1.0.0-arm64", "arm64")
+	opts.SupportedArchitectures = []string{"amd64", "arm64"}
+
+	c := newRemoteTagClient(fake, &registry.RegistryUser{Name: "user", Password: "password"})
+	if err := RemoteTag(context.Background(), c, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// the manifest list is tagged as it is, keeping its digest
+	for _, tag := range opts.Tags {
+		if actual := tagDigest(t, c, "ns/app", tag); actual != index {
+			t.Errorf("expected %s to be the manifest list %v, got %v", tag, index, actual)
+		}
+	}
+}
+
+func TestRemoteTag_withoutArchitectures(t *testing.T) {
+	fake, opts := newRemoteTagRegistry(t)
+	image := fake.PutImage("ns/app", "1.0.0", "amd64")
+
+	c := newRemoteTagClient(fake, &registry.RegistryUser{Name: "user", Password: "password"})
+	if err := RemoteTag(context.Background(), c, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range opts.Tags {
+		if actual := tagDigest(t, c, "ns/app", tag); actual != image.Digest {
+			t.Errorf("expected %s to be %v, got %v", tag, image.Digest, actual)
+		}
+	}
+}
+
+func TestRemoteTag_errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   bool
+		user     *registry.RegistryUser
+		expected error
+	}{
+		{
+			name:     "missing source",
+			user:     &registry.RegistryUser{Name: "user", Password: "password"},
+			expected: registry.ErrNotFound,
+		},
+		{
+			name:     "auth failure",
+			source:   true,
+			user:     &registry.RegistryUser{Name: "user", Password: "wrong"},
+			expected: registry.ErrUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake, opts := newRemoteTagRegistry(t)
+			if tc.source {
+				fake.PutImage("ns/app", "1.0.0", "amd64")
+			}
+
+			err := RemoteTag(context.Background(), newRemoteTagClient(fake, tc.user), opts)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+
+			if count := fake.CountRequests("PUT /v2/ns/app/manifests/"); count != 0 {
+				t.Errorf("expected no tag to be created, got %v", count)
+			}
+		})
+	}
+}
+
+func TestRemoteTag_dryRun(t *testing.T) {
+	fake, opts := newRemoteTagRegistry(t)
+	opts.SupportedArchitectures = []string{"amd64"}
+	opts.DryRun = true
+
+	// nothing is requested, so the missing images and credentials are not an error
+	if err := RemoteTag(context.Background(), newRemoteTagClient(fake, nil), opts); err != nil {
+		t.Fatal(err)
+	}
+	if count := fake.CountRequests(""); count != 0 {
+		t.Errorf("expected no request, got %v", count)
+	}
+}
+
+func TestRemoteTag_noTags(t *testing.T) {
+	fake, opts := newRemoteTagRegistry(t)
+	opts.Tags = nil
+
+	if err := RemoteTag(context.Background(), newRemoteTagClient(fake, nil), opts); err != ErrNoProvidedTags {
+		t.Errorf("expected %v, got %v", ErrNoProvidedTags, err)
+	}
+}
@@ -2,6 +2,8 @@ package image
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"tugboat/internal/driver"
 	"tugboat/internal/term"
 
@@ -65,3 +67,17 @@ func Tag(ctx context.Context, d driver.ImageBuilderPusher, opts TagOptions) erro
 
 	return nil
 }
+
+// TaggedImages returns the source image with its tag replaced by each of the tags
+func TaggedImages(sourceImage string, tags []string) []string {
+	name := sourceImage
+	if refName := RefName(sourceImage); refName != "" {
+		name = strings.TrimSuffix(sourceImage, ":"+refName)
+	}
+
+	var images []string
+	for _, tag := range tags {
+		images = append(images, fmt.Sprintf("%s:%s", name, tag))
+	}
+	return images
+}
@@ -45,6 +45,84 @@ var (
 		Value:      false,
 		Usage:      "Do not use cache when building the image",
 	}
+	TargetFlag = Flag{
+		Name:       "target",
+		ConfigName: "build.target",
+		Value:      "",
+		Usage:      "Set the target build stage to build",
+	}
+	LabelsFlag = Flag{
+		Name:       "label",
+		ConfigName: "build.labels",
+		Value:      []string{},
+		Usage:      "Set metadata for an image in a comma separated string (i.e. --label FOO=bar,BAR=foo)",
+	}
+	PlatformFlag = Flag{
+		Name:       "platform",
+		ConfigName: "build.platform",
+		Value:      "",
+		Usage:      "Set the platform if the server is multi-platform capable (i.e. linux/arm64)",
+	}
+	NetworkFlag = Flag{
+		Name:       "network",
+		ConfigName: "build.network",
+		Value:      "",
+		Usage:      "Set the networking mode for the RUN instructions during build",
+	}
+	AddHostsFlag = Flag{
+		Name:       "add-host",
+		ConfigName: "build.add-hosts",
+		Value:      []string{},
+		Usage:      "Add a custom host-to-IP mapping (i.e. --add-host host:ip)",
+	}
+	ShmSizeFlag = Flag{
+		Name:       "shm-size",
+		ConfigName: "build.shm-size",
+		Value:      "",
+		Usage:      "Size of /dev/shm (i.e. 64m)",
+	}
+	UlimitsFlag = Flag{
+		Name:       "ulimit",
+		ConfigName: "build.ulimits",
+		Value:      []string{},
+		Usage:      "Ulimit options (i.e. --ulimit nofile=1024:2048)",
+	}
+	SshFlag = Flag{
+		Name:       "ssh",
+		ConfigName: "build.ssh",
+		Value:      StringArray{},
+		Usage:      "SSH agent socket or keys to expose to the build (i.e. --ssh default)",
+	}
+	SecretsFlag = Flag{
+		Name:       "secret",
+		ConfigName: "build.secrets",
+		Value:      StringArray{},
+		Usage:      "Secret to expose to the build (i.e. --secret id=mysecret,src=/local/secret)",
+	}
+	CacheFromFlag = Flag{
+		Name:       "cache-from",
+		ConfigName: "build.cache-from",
+		Value:      StringArray{},
+		Usage:      "External cache sources: registry, local, inline or a full spec (i.e. --cache-from type=registry,ref=image:cache)",
+	}
+	CacheToFlag = Flag{
+		Name:       "cache-to",
+		ConfigName: "build.cache-to",
+		Value:      StringArray{},
+		Usage:      "Cache export destinations: registry, local, inline or a full spec (i.e. --cache-to type=local,dest=path)",
+	}
+	OciLabelsFlag = Flag{
+		Name:       "oci-labels",
+		ConfigName: "build.oci-labels.enabled",
+		Value:      true,
+		Usage:      "Add the standard org.opencontainers.image labels to the image (--oci-labels=false disables them, --oci-labels-exclude skips single labels)",
+	}
+	OciLabelsExcludeFlag = Flag{
+		Name:       "oci-labels-exclude",
+		ConfigName: "build.oci-labels.exclude",
+		Value:      []string{},
+		Usage:      "A list of standard labels not to add (i.e. --oci-labels-exclude created,revision)",
+	}
 )
 
 type BuildFlagGroup struct {
@@ -55,6 +133,20 @@ type BuildFlagGroup struct {
 	PushFlag      *Flag
 	PullFlag      *Flag
 	NoCacheFlag   *Flag
+	TargetFlag    *Flag
+	LabelsFlag    *Flag
+	PlatformFlag  *Flag
+	NetworkFlag   *Flag
+	AddHostsFlag  *Flag
+	ShmSizeFlag   *Flag
+	UlimitsFlag   *Flag
+	SshFlag       *Flag
+	SecretsFlag   *Flag
+	CacheFromFlag *Flag
+	CacheToFlag   *Flag
+
+	OciLabelsFlag        *Flag
+	OciLabelsExcludeFlag *Flag
 }
 
 func NewBuildFlagsGroup() *BuildFlagGroup {
@@ -66,6 +158,20 @@ func NewBuildFlagsGroup() *BuildFlagGroup {
 		PushFlag:      &PushFlag,
 		PullFlag:      &PullFlag,
 		NoCacheFlag:   &NoCacheFlag,
+		TargetFlag:    &TargetFlag,
+		LabelsFlag:    &LabelsFlag,
+		PlatformFlag:  &PlatformFlag,
+		NetworkFlag:   &NetworkFlag,
+		AddHostsFlag:  &AddHostsFlag,
+		ShmSizeFlag:   &ShmSizeFlag,
+		UlimitsFlag:   &UlimitsFlag,
+		SshFlag:       &SshFlag,
+		SecretsFlag:   &SecretsFlag,
+		CacheFromFlag: &CacheFromFlag,
+		CacheToFlag:   &CacheToFlag,
+
+		OciLabelsFlag:        &OciLabelsFlag,
+		OciLabelsExcludeFlag: &OciLabelsExcludeFlag,
 	}
 }
 
@@ -74,7 +180,11 @@ func (f *BuildFlagGroup) Name() string {
 }
 
 func (f *BuildFlagGroup) Flags() []*Flag {
-	return []*Flag{f.BuildArgsFlag, f.ContextFlag, f.FileFlag, f.TagsFlag, f.PushFlag, f.PullFlag, f.NoCacheFlag}
+	return []*Flag{
+		f.BuildArgsFlag, f.ContextFlag, f.FileFlag, f.TagsFlag, f.PushFlag, f.PullFlag, f.NoCacheFlag,
+		f.TargetFlag, f.LabelsFlag, f.PlatformFlag, f.NetworkFlag, f.AddHostsFlag, f.ShmSizeFlag, f.UlimitsFlag,
+		f.SshFlag, f.SecretsFlag, f.CacheFromFlag, f.CacheToFlag, f.OciLabelsFlag, f.OciLabelsExcludeFlag,
+	}
 }
 
 func (f *BuildFlagGroup) ToOptions() BuildOptions {
@@ -86,6 +196,21 @@ func (f *BuildFlagGroup) ToOptions() BuildOptions {
 		Push:      getBool(f.PushFlag),
 		Pull:      getBool(f.PullFlag),
 		NoCache:   getBool(f.NoCacheFlag),
+		Target:    getString(f.TargetFlag),
+		Labels:    getStringSlice(f.LabelsFlag),
+		Platform:  getString(f.PlatformFlag),
+		Network:   getString(f.NetworkFlag),
+		AddHosts:  getStringSlice(f.AddHostsFlag),
+		ShmSize:   getString(f.ShmSizeFlag),
+		Ulimits:   getStringSlice(f.UlimitsFlag),
+		Ssh:       getStringArray(f.SshFlag),
+		Secrets:   getStringArray(f.SecretsFlag),
+		CacheFrom: getStringArray(f.CacheFromFlag),
+		CacheTo:   getStringArray(f.CacheToFlag),
+		OciLabels: OciLabelOptions{
+			Enabled: getBool(f.OciLabelsFlag),
+			Exclude: getStringSlice(f.OciLabelsExcludeFlag),
+		},
 	}
 
 	return buildOpts
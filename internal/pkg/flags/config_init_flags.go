@@ -0,0 +1,85 @@
+package flags
+
+var (
+	ConfigInitNameFlag = Flag{
+		Name:       "name",
+		ConfigName: "init.name",
+		Value:      "",
+		Usage:      "The image name, detected from the git remote when empty",
+	}
+	ConfigInitFileFlag = Flag{
+		Name:       "file",
+		Shorthand:  "f",
+		ConfigName: "init.file",
+		Value:      "",
+		Usage:      "The Dockerfile to build, detected from the working directory when empty",
+	}
+	ConfigInitArchitecturesFlag = Flag{
+		Name:       "architectures",
+		Shorthand:  "a",
+		ConfigName: "init.architectures",
+		Value:      []string{},
+		Usage:      "The supported image architectures, detected from the base image when empty",
+	}
+	ConfigInitOutputFlag = Flag{
+		Name:       "output",
+		Shorthand:  "o",
+		ConfigName: "init.output",
+		Value:      "tugboat.yaml",
+		Usage:      "The path of the configuration file to write",
+	}
+	ConfigInitForceFlag = Flag{
+		Name:       "force",
+		ConfigName: "init.force",
+		Value:      false,
+		Usage:      "Overwrite an existing configuration file",
+	}
+	ConfigInitInteractiveFlag = Flag{
+		Name:       "interactive",
+		Shorthand:  "i",
+		ConfigName: "init.interactive",
+		Value:      false,
+		Usage:      "Prompt for each value, using the detected values as the defaults",
+	}
+)
+
+type ConfigInitFlagGroup struct {
+	NameFlag          *Flag
+	FileFlag          *Flag
+	ArchitecturesFlag *Flag
+	OutputFlag        *Flag
+	ForceFlag         *Flag
+	InteractiveFlag   *Flag
+}
+
+func NewConfigInitFlagGroup() *ConfigInitFlagGroup {
+	return &ConfigInitFlagGroup{
+		NameFlag:          &ConfigInitNameFlag,
+		FileFlag:          &ConfigInitFileFlag,
+		ArchitecturesFlag: &ConfigInitArchitecturesFlag,
+		OutputFlag:        &ConfigInitOutputFlag,
+		ForceFlag:         &ConfigInitForceFlag,
+		InteractiveFlag:   &ConfigInitInteractiveFlag,
+	}
+}
+
+func (f *ConfigInitFlagGroup) Name() string {
+	return "ConfigInit"
+}
+
+func (f *ConfigInitFlagGroup) Flags() []*Flag {
+	return []*Flag{f.NameFlag, f.FileFlag, f.ArchitecturesFlag, f.OutputFlag, f.ForceFlag, f.InteractiveFlag}
+}
+
+func (f *ConfigInitFlagGroup) ToOptions() ConfigInitOptions {
+	opts := ConfigInitOptions{
+		Name:          getString(f.NameFlag),
+		File:          getString(f.FileFlag),
+		Architectures: getStringSlice(f.ArchitecturesFlag),
+		Output:        getString(f.OutputFlag),
+		Force:         getBool(f.ForceFlag),
+		Interactive:   getBool(f.InteractiveFlag),
+	}
+
+	return opts
+}
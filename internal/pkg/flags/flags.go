@@ -19,6 +19,9 @@ type Flag struct {
 	// Value is the default value. It must be filled to determine the flag type.
 	Value interface{}
 
+	// Values are the accepted values, any value is accepted when empty.
+	Values []string
+
 	// Usage explains how to use the flag.
 	Usage string
 
@@ -29,11 +32,30 @@ type Flag struct {
 	Deprecated bool
 }
 
+// StringArray is a list of values where each value may contain commas, such as
+// a build secret (i.e. id=mysecret,src=/local/secret)
+type StringArray []string
+
 type FlagGroup interface {
 	Name() string
 	Flags() []*Flag
 }
 
+// AllFlagGroups returns every flag group, the keys of their flags make up the configuration file.
+// The groups of commands that manage the configuration file itself are not included.
+func AllFlagGroups() []FlagGroup {
+	return []FlagGroup{
+		NewGlobalFlagGroup(),
+		NewBuildFlagsGroup(),
+		NewImageFlagsGroup(),
+		NewManifestCreateFlagGroup(),
+		NewPromoteFlagGroup(),
+		NewPruneFlagGroup(),
+		NewTagFlagsGroup(),
+		NewVersionFlagsGroup(),
+	}
+}
+
 func AddFlags(cmd *cobra.Command, f ...FlagGroup) {
 	for _, group := range f {
 		for _, flag := range group.Flags() {
@@ -51,25 +73,40 @@ func Bind(cmd *cobra.Command, f FlagGroup) error {
 	return nil
 }
 
-func ToOptions(globalFlags *GlobalFlagGroup, f ...FlagGroup) *Options {
+func ToOptions(globalFlags *GlobalFlagGroup, f ...FlagGroup) (*Options, error) {
+	globalOpts, err := globalFlags.ToOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	opts := &Options{
-		Global: globalFlags.ToOptions(),
+		Global: globalOpts,
 	}
 
 	for _, flagGroup := range f {
 		switch v := flagGroup.(type) {
 		case *BuildFlagGroup:
 			opts.Build = v.ToOptions()
+		case *ConfigInitFlagGroup:
+			opts.Config.Init = v.ToOptions()
 		case *ImageFlagGroup:
-			opts.Image = v.ToOptions()
+			if opts.Image, err = v.ToOptions(); err != nil {
+				return nil, err
+			}
 		case *ManifestCreateFlagGroup:
 			opts.Manifest.Create = v.ToOptions()
+		case *PromoteFlagGroup:
+			opts.Promote = v.ToOptions()
+		case *PruneFlagGroup:
+			opts.Prune = v.ToOptions()
 		case *TagFlagGroup:
 			opts.Tag = v.ToOptions()
 		case *VersionFlagGroup:
 			opts.Version = v.ToOptions()
+		case *VersionNextFlagGroup:
+			opts.Version.Next = v.ToOptions()
 		}
 	}
 
-	return opts
+	return opts, nil
 }
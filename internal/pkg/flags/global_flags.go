@@ -2,10 +2,14 @@ package flags
 
 import (
 	"context"
+	"time"
 	"tugboat/internal/pkg/git"
 	"tugboat/internal/version"
 )
 
+// ConfigVersionName is the key of the configuration file layout version
+const ConfigVersionName = "version"
+
 var (
 	ConfigFileFlag = Flag{
 		Name:       "config",
@@ -15,6 +19,14 @@ var (
 		Usage:      "Custom path to a configuration file (optional)",
 		Persistent: true,
 	}
+	ProfileFlag = Flag{
+		Name:       "profile",
+		ConfigName: "profile",
+		Shorthand:  "p",
+		Value:      []string{},
+		Usage:      "The profiles of the configuration file to apply, the profiles matching the git branch or tag are applied when omitted",
+		Persistent: true,
+	}
 	DryRunFlag = Flag{
 		Name:       "dry-run",
 		ConfigName: "options.dry-run",
@@ -32,7 +44,7 @@ var (
 	}
 	OfficialFlag = Flag{
 		Name:       "official",
-		ConfigName: "publish.official",
+		ConfigName: "options.official",
 		Value:      false,
 		Usage:      "Mimic the official docker publish method for images in private registries",
 		Persistent: true,
@@ -56,20 +68,82 @@ var (
 		Name:       "registry-user",
 		ConfigName: "registry.user",
 		Value:      "",
-		Usage:      "The username credential with access to the registry",
+		Usage:      "The username credential with access to the registry (supports env:VAR and file:/path)",
 		Persistent: true,
 	}
 	RegistryPasswordFlag = Flag{
 		Name:       "registry-password",
 		ConfigName: "registry.password",
 		Value:      "",
-		Usage:      "The password credential with access to the registry",
+		Usage:      "The password credential with access to the registry (supports env:VAR and file:/path)",
+		Persistent: true,
+	}
+	RegistryPasswordFileFlag = Flag{
+		Name:       "registry-password-file",
+		ConfigName: "registry.password-file",
+		Value:      "",
+		Usage:      "Read the registry password from a file",
+		Persistent: true,
+	}
+	RegistryPasswordStdinFlag = Flag{
+		Name:       "registry-password-stdin",
+		ConfigName: "registry.password-stdin",
+		Value:      false,
+		Usage:      "Read the registry password from stdin",
+		Persistent: true,
+	}
+	RegistryInsecureFlag = Flag{
+		Name:       "registry-insecure",
+		ConfigName: "registry.insecure",
+		Value:      false,
+		Usage:      "Allow plain http and skip the verification of the registry certificate",
+		Persistent: true,
+	}
+	RegistryCaFileFlag = Flag{
+		Name:       "registry-ca-file",
+		ConfigName: "registry.ca-file",
+		Value:      "",
+		Usage:      "Trust the certificates signed by this CA file when connecting to the registry",
 		Persistent: true,
 	}
+	RegistryClientCertFlag = Flag{
+		Name:       "registry-client-cert",
+		ConfigName: "registry.client-cert",
+		Value:      "",
+		Usage:      "The client certificate file used to authenticate with the registry",
+		Persistent: true,
+	}
+	RegistryClientKeyFlag = Flag{
+		Name:       "registry-client-key",
+		ConfigName: "registry.client-key",
+		Value:      "",
+		Usage:      "The client key file used to authenticate with the registry",
+		Persistent: true,
+	}
+	RegistryRateLimitWaitFlag = Flag{
+		Name:       "registry-rate-limit-wait",
+		ConfigName: "registry.rate-limit-wait",
+		Value:      time.Duration(0),
+		Usage:      "The longest time to wait for the registry rate limit to reset before failing (i.e. 10m, 0 fails immediately)",
+		Persistent: true,
+	}
+	RegistryCredentialProviderFlag = Flag{
+		Name:       "",
+		ConfigName: "registry.credential-provider",
+		Value:      CredentialProviderOptions{},
+		Usage:      "An executable that prints short-lived registry credentials",
+	}
+	RegistriesFlag = Flag{
+		Name:       "",
+		ConfigName: "registries",
+		Value:      []RegistryOptions{},
+		Usage:      "Additional registries the images are published to",
+	}
 	DriverNameFlag = Flag{
 		Name:       "driver",
 		ConfigName: "driver.name",
 		Value:      "auto",
+		Values:     []string{"auto", "docker"},
 		Usage:      "The driver to use to manage containers",
 		Persistent: true,
 	}
@@ -80,14 +154,24 @@ type DriverFlagGroup struct {
 }
 
 type RegistryFlagGroup struct {
-	RegistryUrlFlag *Flag
-	NamespaceFlag   *Flag
-	UsernameFlag    *Flag
-	PasswordFlag    *Flag
+	RegistryUrlFlag        *Flag
+	NamespaceFlag          *Flag
+	UsernameFlag           *Flag
+	PasswordFlag           *Flag
+	PasswordFileFlag       *Flag
+	PasswordStdinFlag      *Flag
+	InsecureFlag           *Flag
+	CaFileFlag             *Flag
+	ClientCertFlag         *Flag
+	ClientKeyFlag          *Flag
+	RateLimitWaitFlag      *Flag
+	CredentialProviderFlag *Flag
+	RegistriesFlag         *Flag
 }
 
 type GlobalFlagGroup struct {
 	ConfigFileFlag    *Flag
+	ProfileFlag       *Flag
 	DebugFlag         *Flag
 	DryRunFlag        *Flag
 	DriverFlagGroup   *DriverFlagGroup
@@ -98,16 +182,26 @@ type GlobalFlagGroup struct {
 func NewGlobalFlagGroup() *GlobalFlagGroup {
 	return &GlobalFlagGroup{
 		ConfigFileFlag: &ConfigFileFlag,
+		ProfileFlag:    &ProfileFlag,
 		DebugFlag:      &DebugFlag,
 		DryRunFlag:     &DryRunFlag,
 		DriverFlagGroup: &DriverFlagGroup{
 			NameFlag: &DriverNameFlag,
 		},
 		RegistryFlagGroup: &RegistryFlagGroup{
-			RegistryUrlFlag: &RegistryUrlFlag,
-			NamespaceFlag:   &RegistryNamespaceFlag,
-			UsernameFlag:    &RegistryUsernameFlag,
-			PasswordFlag:    &RegistryPasswordFlag,
+			RegistryUrlFlag:        &RegistryUrlFlag,
+			NamespaceFlag:          &RegistryNamespaceFlag,
+			UsernameFlag:           &RegistryUsernameFlag,
+			PasswordFlag:           &RegistryPasswordFlag,
+			PasswordFileFlag:       &RegistryPasswordFileFlag,
+			PasswordStdinFlag:      &RegistryPasswordStdinFlag,
+			InsecureFlag:           &RegistryInsecureFlag,
+			CaFileFlag:             &RegistryCaFileFlag,
+			ClientCertFlag:         &RegistryClientCertFlag,
+			ClientKeyFlag:          &RegistryClientKeyFlag,
+			RateLimitWaitFlag:      &RegistryRateLimitWaitFlag,
+			CredentialProviderFlag: &RegistryCredentialProviderFlag,
+			RegistriesFlag:         &RegistriesFlag,
 		},
 		OfficialFlag: &OfficialFlag,
 	}
@@ -118,41 +212,69 @@ func (f *GlobalFlagGroup) Name() string {
 }
 
 func (f *GlobalFlagGroup) Flags() []*Flag {
-	return []*Flag{f.ConfigFileFlag, f.DebugFlag, f.DryRunFlag, f.OfficialFlag, f.DriverFlagGroup.NameFlag, f.RegistryFlagGroup.RegistryUrlFlag, f.RegistryFlagGroup.NamespaceFlag, f.RegistryFlagGroup.UsernameFlag, f.RegistryFlagGroup.PasswordFlag}
+	return []*Flag{f.ConfigFileFlag, f.ProfileFlag, f.DebugFlag, f.DryRunFlag, f.OfficialFlag, f.DriverFlagGroup.NameFlag, f.RegistryFlagGroup.RegistryUrlFlag, f.RegistryFlagGroup.NamespaceFlag, f.RegistryFlagGroup.UsernameFlag, f.RegistryFlagGroup.PasswordFlag, f.RegistryFlagGroup.PasswordFileFlag, f.RegistryFlagGroup.PasswordStdinFlag, f.RegistryFlagGroup.InsecureFlag, f.RegistryFlagGroup.CaFileFlag, f.RegistryFlagGroup.ClientCertFlag, f.RegistryFlagGroup.ClientKeyFlag, f.RegistryFlagGroup.RateLimitWaitFlag, f.RegistryFlagGroup.CredentialProviderFlag, f.RegistryFlagGroup.RegistriesFlag}
 }
 
-func (f *GlobalFlagGroup) ToOptions() GlobalOptions {
+func (f *GlobalFlagGroup) ToOptions() (GlobalOptions, error) {
 	ctx := context.TODO()
 	gitFullCommit, _ := git.Clean(git.Run(ctx, "rev-parse HEAD"))
 	gitShortCommit, _ := git.Clean(git.Run(ctx, "log -1 --pretty=%h"))
 	gitBranch, _ := git.Clean(git.Run(ctx, "rev-parse --abbrev-ref HEAD"))
 	gitTag, _ := git.Clean(git.Run(ctx, "describe --tags"))
+	gitRemote, _ := git.Clean(git.Run(ctx, "config --get remote.origin.url"))
+	gitRemote = git.StripCredentials(gitRemote)
+
+	credentialProvider, err := getCredentialProvider(f.RegistryFlagGroup.CredentialProviderFlag)
+	if err != nil {
+		return GlobalOptions{}, err
+	}
+
+	registries, err := getRegistries(f.RegistryFlagGroup.RegistriesFlag)
+	if err != nil {
+		return GlobalOptions{}, err
+	}
 
 	opts := GlobalOptions{
 		ConfigFile: getString(f.ConfigFileFlag),
+		Profiles:   getStringSlice(f.ProfileFlag),
 		Debug:      getBool(f.DebugFlag),
 		DryRun:     getBool(f.DryRunFlag),
 		Driver: DriverOptions{
 			Name: getString(f.DriverFlagGroup.NameFlag),
 		},
 		Registry: RegistryOptions{
-			Url:       getString(f.RegistryFlagGroup.RegistryUrlFlag),
-			Namespace: getString(f.RegistryFlagGroup.NamespaceFlag),
-			Username:  getString(f.RegistryFlagGroup.UsernameFlag),
-			Password:  getString(f.RegistryFlagGroup.PasswordFlag),
+			Url:                getString(f.RegistryFlagGroup.RegistryUrlFlag),
+			Namespace:          getString(f.RegistryFlagGroup.NamespaceFlag),
+			Username:           getString(f.RegistryFlagGroup.UsernameFlag),
+			Password:           getString(f.RegistryFlagGroup.PasswordFlag),
+			PasswordFile:       getString(f.RegistryFlagGroup.PasswordFileFlag),
+			PasswordStdin:      getBool(f.RegistryFlagGroup.PasswordStdinFlag),
+			Insecure:           getBool(f.RegistryFlagGroup.InsecureFlag),
+			CaFile:             getString(f.RegistryFlagGroup.CaFileFlag),
+			ClientCert:         getString(f.RegistryFlagGroup.ClientCertFlag),
+			ClientKey:          getString(f.RegistryFlagGroup.ClientKeyFlag),
+			RateLimitWait:      getDuration(f.RegistryFlagGroup.RateLimitWaitFlag),
+			CredentialProvider: credentialProvider,
 		},
-		Official: getBool(f.OfficialFlag),
+		Registries: registries,
+		Official:   getBool(f.OfficialFlag),
 		Git: Git{
 			Branch:      gitBranch,
 			Commit:      gitFullCommit,
 			FullCommit:  gitFullCommit,
 			ShortCommit: gitShortCommit,
 			Tag:         gitTag,
+			Remote:      gitRemote,
 		},
 		Version: Version{
-			App: version.GetVersion(),
+			App:    version.GetVersion(),
+			Config: settings().GetString(ConfigVersionName),
 		},
 	}
 
-	return opts
+	if err := checkPasswordStdin(append([]RegistryOptions{opts.Registry}, opts.Registries...)...); err != nil {
+		return GlobalOptions{}, err
+	}
+
+	return opts, nil
 }
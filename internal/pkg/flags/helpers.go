@@ -3,17 +3,38 @@ package flags
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"regexp"
-	"slices"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// entrySettings are the settings of the entry of the images list the options are read for (see
+// ToImageOptions), the top-level settings are used when nil
+var entrySettings *viper.Viper
+
+// settings returns where the values of the flags are read from
+func settings() *viper.Viper {
+	if entrySettings != nil {
+		return entrySettings
+	}
+	return viper.GetViper()
+}
+
+// EnvPrefix is prepended to the environment variables of the config names
+const EnvPrefix = "TUGBOAT"
+
+// boundFlags are the command line flags bound to each config name
+var boundFlags = make(map[string]*pflag.Flag)
+
+// boundEnv are the config names bound to environment variables
+var boundEnv = make(map[string]bool)
+
 func addFlag(cmd *cobra.Command, flag *Flag) {
 	if flag == nil || flag.Name == "" {
 		return
@@ -26,18 +47,23 @@ func addFlag(cmd *cobra.Command, flag *Flag) {
 		flags = cmd.Flags()
 	}
 
+	// document the environment variable of the flag (i.e. --push [$TUGBOAT_BUILD_PUSH])
+	usage := fmt.Sprintf("%s [$%s]", flag.Usage, EnvName(flag.ConfigName))
+
 	// Evaluate what type of flag should being added
 	switch v := flag.Value.(type) {
 	case int:
-		flags.IntP(flag.Name, flag.Shorthand, v, flag.Usage)
+		flags.IntP(flag.Name, flag.Shorthand, v, usage)
 	case string:
-		flags.StringP(flag.Name, flag.Shorthand, v, flag.Usage)
+		flags.StringP(flag.Name, flag.Shorthand, v, usage)
 	case []string:
-		flags.StringSliceP(flag.Name, flag.Shorthand, v, flag.Usage)
+		flags.StringSliceP(flag.Name, flag.Shorthand, v, usage)
+	case StringArray:
+		flags.StringArrayP(flag.Name, flag.Shorthand, v, usage)
 	case bool:
-		flags.BoolP(flag.Name, flag.Shorthand, v, flag.Usage)
+		flags.BoolP(flag.Name, flag.Shorthand, v, usage)
 	case time.Duration:
-		flags.DurationP(flag.Name, flag.Shorthand, v, flag.Usage)
+		flags.DurationP(flag.Name, flag.Shorthand, v, usage)
 	}
 
 	if flag.Deprecated {
@@ -48,7 +74,17 @@ func addFlag(cmd *cobra.Command, flag *Flag) {
 func bind(cmd *cobra.Command, flag *Flag) error {
 	if flag == nil {
 		return nil
-	} else if flag.Name == "" {
+	}
+
+	// the prefixed variable takes precedence over the deprecated unprefixed one (i.e. TUGBOAT_BUILD_PUSH, BUILD_PUSH)
+	if legacy := LegacyEnvName(flag.ConfigName); legacy != "" {
+		viper.BindEnv(flag.ConfigName, EnvName(flag.ConfigName), legacy)
+	} else {
+		viper.BindEnv(flag.ConfigName, EnvName(flag.ConfigName))
+	}
+	boundEnv[flag.ConfigName] = true
+
+	if flag.Name == "" {
 		// This flag is only available in the config file
 		viper.SetDefault(flag.ConfigName, flag.Value)
 		return nil
@@ -58,44 +94,108 @@ func bind(cmd *cobra.Command, flag *Flag) error {
 		if err := viper.BindPFlag(flag.ConfigName, cmd.PersistentFlags().Lookup(flag.Name)); err != nil {
 			return err
 		}
+		boundFlags[flag.ConfigName] = cmd.PersistentFlags().Lookup(flag.Name)
 	} else {
 		// fmt.Println("Config Name:", flag.ConfigName, " Flag Name:", cmd.Flags().Lookup(flag.Name))
 		if err := viper.BindPFlag(flag.ConfigName, cmd.Flags().Lookup(flag.Name)); err != nil {
 			return err
 		}
+		boundFlags[flag.ConfigName] = cmd.Flags().Lookup(flag.Name)
 	}
 
-	if strings.Contains(flag.ConfigName, "-") {
-		str := flag.ConfigName
-		replace_chars := []string{"-", "."}
+	// Bind the yaml configs to an env var (i.e log.format -> TUGBOAT_LOG_FORMAT)
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	return nil
+}
+
+// EnvName returns the environment variable a config name is read from (i.e. build.no-cache -> TUGBOAT_BUILD_NO_CACHE)
+func EnvName(configName string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(configName))
+}
+
+// legacyEnvNames are the environment variables without the prefix that were read before the prefix
+// was added, the keys added since are only read from the prefixed variables
+var legacyEnvNames = map[string]string{
+	"config":                        "CONFIG",
+	"options.debug":                 "OPTIONS_DEBUG",
+	"options.dry-run":               "OPTIONS_DRY_RUN",
+	"options.official":              "PUBLISH_OFFICIAL",
+	"options.version.short":         "OPTIONS_VERSION_SHORT",
+	"driver.name":                   "DRIVER_NAME",
+	"registry.url":                  "REGISTRY_URL",
+	"registry.namespace":            "REGISTRY_NAMESPACE",
+	"registry.user":                 "REGISTRY_USER",
+	"registry.password":             "REGISTRY_PASSWORD",
+	"build.args":                    "BUILD_ARGS",
+	"build.context":                 "BUILD_CONTEXT",
+	"build.file":                    "BUILD_FILE",
+	"build.no-cache":                "BUILD_NO_CACHE",
+	"build.pull":                    "BUILD_PULL",
+	"build.push":                    "BUILD_PUSH",
+	"build.tags":                    "BUILD_TAGS",
+	"image.name":                    "IMAGE_NAME",
+	"image.supported-architectures": "IMAGE_SUPPORTED_ARCHITECTURES",
+	"image.version":                 "IMAGE_VERSION",
+	"manifest.create.for":           "MANIFEST_CREATE_FOR",
+	"manifest.create.latest":        "MANIFEST_CREATE_LATEST",
+	"manifest.create.push":          "MANIFEST_CREATE_PUSH",
+	"tag.push":                      "TAG_PUSH",
+}
+
+// LegacyEnvName returns the environment variable without the prefix (i.e. build.no-cache -> BUILD_NO_CACHE),
+// which is still read but deprecated since generic CI variables such as IMAGE_NAME override the config.
+// An empty string is returned for the keys that never had one.
+func LegacyEnvName(configName string) string {
+	return legacyEnvNames[configName]
+}
 
-		for _, char := range replace_chars {
-			str = strings.ReplaceAll(str, char, "_")
+// LookupEnv returns the environment variable setting the config name, an empty string is returned
+// when none is set
+func LookupEnv(configName string) string {
+	for _, name := range []string{EnvName(configName), LegacyEnvName(configName)} {
+		if name != "" && os.Getenv(name) != "" {
+			return name
 		}
-		str = strings.ToUpper(str)
+	}
+	return ""
+}
 
-		viper.BindEnv(flag.ConfigName, str)
+// WarnDeprecatedEnv warns about each unprefixed environment variable that sets a config name
+func WarnDeprecatedEnv() {
+	var configNames []string
+	for configName := range boundEnv {
+		configNames = append(configNames, configName)
 	}
+	sort.Strings(configNames)
 
-	// Bind the yaml configs to an env var (i.e log.format -> LOG_FORMAT)
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+	for _, configName := range configNames {
+		if legacy := LegacyEnvName(configName); legacy != "" && LookupEnv(configName) == legacy {
+			log.Warnf("%s is deprecated and will no longer be read in a future release, use %s instead", legacy, EnvName(configName))
+		}
+	}
+}
 
-	return nil
+// FlagChanged returns true when the flag bound to the config name is set on the command line
+func FlagChanged(configName string) bool {
+	flag, ok := boundFlags[configName]
+	return ok && flag != nil && flag.Changed
 }
 
 func getString(flag *Flag) string {
 	if flag == nil {
 		return ""
 	}
-	return viper.GetString(flag.ConfigName)
+	return settings().GetString(flag.ConfigName)
 }
 
 func getStringSlice(flag *Flag) []string {
 	if flag == nil {
 		return nil
 	}
-	v := viper.GetStringSlice(flag.ConfigName)
+	v := settings().GetStringSlice(flag.ConfigName)
 
 	// Separate env values containing a ','
 	switch {
@@ -107,112 +207,77 @@ func getStringSlice(flag *Flag) []string {
 	return v
 }
 
-func getBool(flag *Flag) bool {
+// getStringArray returns the values of the flag without splitting on a ','
+func getStringArray(flag *Flag) []string {
 	if flag == nil {
-		return false
+		return nil
 	}
-	return viper.GetBool(flag.ConfigName)
-}
-
-type sanitizedInput struct {
-	RawInput string
-	Value    string
-	IsCmd    bool
+	v := settings().GetStringSlice(flag.ConfigName)
+	if len(v) == 0 {
+		return nil
+	}
+	return v
 }
 
-// evaluateValue will attempt to safely evaluate a variable that has been set to a bash expression
-// but not run any string as a command unless it is prefixed with `$`. Valid formats are $VALUE, ${VALUE}, $(cat VALUE).
-// allowed bash commands to run are defined in the sanitizeInput function
-func evaluateValue(value string) (string, error) {
-	// if the string does not start with a $ don't evaluate it
-	if !strings.HasPrefix(value, "$") {
-		return value, nil
+// getRegistries returns the list of registries defined in the config file
+func getRegistries(flag *Flag) ([]RegistryOptions, error) {
+	if flag == nil {
+		return nil, nil
 	}
 
-	value, err := executeBashCmd(value)
-	if err != nil {
-		return "", err
+	var registries []RegistryOptions
+	if err := settings().UnmarshalKey(flag.ConfigName, &registries); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", flag.ConfigName)
 	}
-
-	return value, nil
+	return registries, nil
 }
 
-// executeBashCmd sanitizes a bash command and executes it.
-func executeBashCmd(input string) (string, error) {
-	sanitizedInput, err := sanitizeInput(input)
-	if err != nil {
-		return "", err
+// checkPasswordStdin rejects the password stdin on more than one registry, stdin only holds the
+// password of one registry
+func checkPasswordStdin(registries ...RegistryOptions) error {
+	var urls []string
+	for _, registry := range registries {
+		if registry.PasswordStdin {
+			urls = append(urls, registry.Url)
+		}
 	}
 
-	if !sanitizedInput.IsCmd {
-		return sanitizedInput.Value, nil
+	if len(urls) > 1 {
+		return errors.Errorf("password-stdin is set on the registries %s, only one registry can read its password from stdin", strings.Join(urls, ", "))
 	}
+	return nil
+}
 
-	cmd := exec.Command("bash", "-c", input)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+func getInt(flag *Flag) int {
+	if flag == nil {
+		return 0
 	}
-	return trim(string(output)), nil
+	return settings().GetInt(flag.ConfigName)
 }
 
-func sanitizeInput(input string) (*sanitizedInput, error) {
-	var sanitizedValue string
-	allowedCommands := []string{"echo", "cat", "git"}
-	disallowedCharacters := []string{";", "|", "&"}
-
-	// Use a regular expression to extract Bash expressions from the input string
-	r := regexp.MustCompile(`\$\(.+?\)|\${.+?}|\$[A-Z0-9_]+`)
-	match := r.FindString(input)
-
-	// Execute the Bash expressions and replace them with their output
-
-	// Check if the match is a $(VALUE) pattern
-	if strings.HasPrefix(match, "$(") && strings.HasSuffix(match, ")") {
-		// extract the expression to execute if it contains allowed commands
-		sanitizedValue = strings.Replace(input, match, match[2:len(match)-1], -1)
-	} else {
-		// Check if the match is a ${VALUE} pattern
-		if strings.HasPrefix(match, "${") && strings.HasSuffix(match, "}") {
-			cmd := exec.Command("bash", "-c", "echo "+match)
-			output, err := cmd.Output()
-			if err != nil {
-				return nil, err
-			}
-			sanitizedValue = strings.Replace(input, match, string(output), -1)
-			return &sanitizedInput{RawInput: input, Value: trim(sanitizedValue), IsCmd: false}, nil
-		}
-
-		// Check if the match is a $VALUE pattern
-		if strings.HasPrefix(match, "$") {
-			// Get the value of the environment variable
-			value, ok := os.LookupEnv(match[1:])
-			if !ok {
-				return nil, fmt.Errorf("undefined environment variable: %s", match[1:])
-			}
-			sanitizedValue = strings.Replace(input, match, value, -1)
-			return &sanitizedInput{RawInput: input, Value: trim(sanitizedValue), IsCmd: false}, nil
-		}
+func getDuration(flag *Flag) time.Duration {
+	if flag == nil {
+		return 0
 	}
+	return settings().GetDuration(flag.ConfigName)
+}
 
-	// Split the input into words
-	words := strings.Fields(sanitizedValue)
-
-	// Check if the first word is an allowed command
-	if !slices.Contains(allowedCommands, words[0]) {
-		return nil, fmt.Errorf("disallowed command: %s", words[0])
+func getBool(flag *Flag) bool {
+	if flag == nil {
+		return false
 	}
+	return settings().GetBool(flag.ConfigName)
+}
 
-	// Check if the input contains any disallowed characters
-	for _, char := range disallowedCharacters {
-		if strings.Contains(sanitizedValue, char) {
-			return nil, fmt.Errorf("disallowed character: %s", char)
-		}
+// getCredentialProvider returns the credential provider defined in the config file
+func getCredentialProvider(flag *Flag) (CredentialProviderOptions, error) {
+	var provider CredentialProviderOptions
+	if flag == nil {
+		return provider, nil
 	}
 
-	return &sanitizedInput{RawInput: input, Value: trim(sanitizedValue), IsCmd: true}, nil
-}
-
-func trim(input string) string {
-	return strings.TrimSuffix(input, "\n")
+	if err := settings().UnmarshalKey(flag.ConfigName, &provider); err != nil {
+		return CredentialProviderOptions{}, errors.Wrapf(err, "reading %s", flag.ConfigName)
+	}
+	return provider, nil
 }
@@ -0,0 +1,253 @@
+package flags
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Test_getRegistries(t *testing.T) {
+	defer viper.Reset()
+
+	config := `
+registries:
+  - url: ghcr.io
+    namespace: org
+    user: env:GHCR_USER
+    password: env:GHCR_TOKEN
+  - url: harbor.example.com
+    namespace: mirror
+    user: robot
+    password-file: /run/secrets/harbor
+  - url: 123456789012.dkr.ecr.us-east-1.amazonaws.com
+    credential-provider:
+      command: ecr-credential-provider
+`
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	registries, err := getRegistries(&RegistriesFlag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registries) != 3 {
+		t.Fatalf("expected 3 registries, got %v", len(registries))
+	}
+
+	expected := RegistryOptions{Url: "ghcr.io", Namespace: "org", Username: "env:GHCR_USER", Password: "env:GHCR_TOKEN"}
+	if !reflect.DeepEqual(expected, registries[0]) {
+		t.Errorf("expected %#v, got %#v", expected, registries[0])
+	}
+
+	expected = RegistryOptions{Url: "harbor.example.com", Namespace: "mirror", Username: "robot", PasswordFile: "/run/secrets/harbor"}
+	if !reflect.DeepEqual(expected, registries[1]) {
+		t.Errorf("expected %#v, got %#v", expected, registries[1])
+	}
+
+	expected = RegistryOptions{Url: "123456789012.dkr.ecr.us-east-1.amazonaws.com", CredentialProvider: CredentialProviderOptions{Command: "ecr-credential-provider"}}
+	if !reflect.DeepEqual(expected, registries[2]) {
+		t.Errorf("expected %#v, got %#v", expected, registries[2])
+	}
+}
+
+func Test_getRegistries_invalid(t *testing.T) {
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("registries:\n  - ghcr.io\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := getRegistries(&RegistriesFlag); err == nil || !strings.Contains(err.Error(), "reading registries") {
+		t.Errorf("expected an error reading the registries, got %v", err)
+	}
+}
+
+func Test_getCredentialProvider(t *testing.T) {
+	defer viper.Reset()
+
+	// the default is used when the config does not define a provider
+	viper.SetDefault(RegistryCredentialProviderFlag.ConfigName, RegistryCredentialProviderFlag.Value)
+	if provider, err := getCredentialProvider(&RegistryCredentialProviderFlag); err != nil || !reflect.DeepEqual(CredentialProviderOptions{}, provider) {
+		t.Errorf("expected no credential provider, got %#v, %v", provider, err)
+	}
+
+	config := `
+registry:
+  url: 123456789012.dkr.ecr.us-east-1.amazonaws.com
+  credential-provider:
+    command: /usr/local/bin/ecr-credential-provider
+    args:
+      - --region
+      - us-east-1
+    env:
+      - AWS_PROFILE=ci
+`
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CredentialProviderOptions{
+		Command: "/usr/local/bin/ecr-credential-provider",
+		Args:    []string{"--region", "us-east-1"},
+		Env:     []string{"AWS_PROFILE=ci"},
+	}
+	if provider, err := getCredentialProvider(&RegistryCredentialProviderFlag); err != nil || !reflect.DeepEqual(expected, provider) {
+		t.Errorf("expected %#v, got %#v, %v", expected, provider, err)
+	}
+}
+
+func Test_getCredentialProvider_invalid(t *testing.T) {
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("registry:\n  credential-provider:\n    - ecr-credential-provider\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := getCredentialProvider(&RegistryCredentialProviderFlag); err == nil || !strings.Contains(err.Error(), "reading registry.credential-provider") {
+		t.Errorf("expected an error reading the credential provider, got %v", err)
+	}
+}
+
+func TestLegacyEnvName(t *testing.T) {
+	testCases := map[string]string{
+		"build.no-cache":         "BUILD_NO_CACHE",
+		"options.official":       "PUBLISH_OFFICIAL",
+		"build.network":          "",
+		"profile":                "",
+		"prune.keep-last":        "",
+		"registry.password-file": "",
+	}
+	for configName, expected := range testCases {
+		if actual := LegacyEnvName(configName); actual != expected {
+			t.Errorf("expected %v to be %q, got %q", configName, expected, actual)
+		}
+	}
+}
+
+func Test_bindEnv(t *testing.T) {
+	defer viper.Reset()
+
+	buildFlags := NewBuildFlagsGroup()
+	cmd := &cobra.Command{Use: "build"}
+	AddFlags(cmd, buildFlags)
+	Bind(cmd, buildFlags)
+
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{name: "prefixed", env: map[string]string{"TUGBOAT_BUILD_FILE": "Dockerfile.prefixed"}, expected: "Dockerfile.prefixed"},
+		{name: "deprecated", env: map[string]string{"BUILD_FILE": "Dockerfile.legacy"}, expected: "Dockerfile.legacy"},
+		{name: "prefixed takes precedence", env: map[string]string{"TUGBOAT_BUILD_FILE": "Dockerfile.prefixed", "BUILD_FILE": "Dockerfile.legacy"}, expected: "Dockerfile.prefixed"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TUGBOAT_BUILD_FILE", "")
+			t.Setenv("BUILD_FILE", "")
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			if actual := getString(buildFlags.FileFlag); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	t.Setenv("TUGBOAT_BUILD_SHM_SIZE", "64m")
+	if actual := getString(buildFlags.ShmSizeFlag); actual != "64m" {
+		t.Errorf("expected 64m, got %v", actual)
+	}
+
+	// the keys added with the prefix have no unprefixed variable
+	t.Setenv("TUGBOAT_BUILD_NETWORK", "")
+	t.Setenv("BUILD_NETWORK", "host")
+	if actual := getString(buildFlags.NetworkFlag); actual != "" {
+		t.Errorf("expected BUILD_NETWORK to be ignored, got %v", actual)
+	}
+	if actual := LookupEnv(buildFlags.NetworkFlag.ConfigName); actual != "" {
+		t.Errorf("expected no environment variable, got %v", actual)
+	}
+
+	if usage := cmd.Flags().Lookup("no-cache").Usage; !strings.HasSuffix(usage, "[$TUGBOAT_BUILD_NO_CACHE]") {
+		t.Errorf("expected the environment variable in the usage, got %v", usage)
+	}
+}
+
+func TestWarnDeprecatedEnv(t *testing.T) {
+	defer viper.Reset()
+
+	buildFlags := NewBuildFlagsGroup()
+	cmd := &cobra.Command{Use: "build"}
+	AddFlags(cmd, buildFlags)
+	Bind(cmd, buildFlags)
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	t.Setenv("BUILD_PUSH", "true")
+	t.Setenv("BUILD_PULL", "true")
+	t.Setenv("TUGBOAT_BUILD_PULL", "false")
+	WarnDeprecatedEnv()
+
+	if !strings.Contains(output.String(), "BUILD_PUSH is deprecated") || !strings.Contains(output.String(), "use TUGBOAT_BUILD_PUSH instead") {
+		t.Errorf("expected a warning for BUILD_PUSH, got %q", output.String())
+	}
+	if strings.Contains(output.String(), "BUILD_PULL is deprecated") {
+		t.Errorf("expected no warning when the prefixed variable is set, got %q", output.String())
+	}
+}
+
+func Test_checkPasswordStdin(t *testing.T) {
+	testCases := []struct {
+		name       string
+		registries []RegistryOptions
+		wantErr    bool
+	}{
+		{name: "no registry", registries: nil},
+		{name: "one registry", registries: []RegistryOptions{{Url: "docker.io", PasswordStdin: true}, {Url: "ghcr.io"}}},
+		{name: "several registries", registries: []RegistryOptions{{Url: "docker.io", PasswordStdin: true}, {Url: "ghcr.io", PasswordStdin: true}}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkPasswordStdin(tc.registries...); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGlobalFlagGroup_ToOptions_passwordStdin(t *testing.T) {
+	defer viper.Reset()
+
+	config := `
+registry:
+  url: docker.io
+  password-stdin: true
+registries:
+  - url: ghcr.io
+    password-stdin: true
+`
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewGlobalFlagGroup().ToOptions()
+	if err == nil || !strings.Contains(err.Error(), "docker.io, ghcr.io") {
+		t.Errorf("expected an error naming both registries, got %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package flags
 
-import log "github.com/sirupsen/logrus"
+import (
+	"context"
+	"tugboat/internal/pkg/git"
+
+	"github.com/pkg/errors"
+)
 
 var (
 	ImageNameFlag = Flag{
@@ -22,12 +27,20 @@ var (
 		Value:      "",
 		Usage:      "Define the version of your application",
 	}
+	ImageVersionStrategyFlag = Flag{
+		Name:       "",
+		ConfigName: "image.version-strategy",
+		Value:      "",
+		Values:     git.VersionStrategies,
+		Usage:      "Derive the version from the git history when no version is defined",
+	}
 )
 
 type ImageFlagGroup struct {
 	ImageNameFlag          *Flag
 	ImageArchitecturesFlag *Flag
 	ImageVersionFlag       *Flag
+	VersionStrategyFlag    *Flag
 }
 
 func NewImageFlagsGroup() *ImageFlagGroup {
@@ -35,6 +48,7 @@ func NewImageFlagsGroup() *ImageFlagGroup {
 		ImageNameFlag:          &ImageNameFlag,
 		ImageArchitecturesFlag: &ImageArchitecturesFlag,
 		ImageVersionFlag:       &ImageVersionFlag,
+		VersionStrategyFlag:    &ImageVersionStrategyFlag,
 	}
 }
 
@@ -43,20 +57,25 @@ func (f *ImageFlagGroup) Name() string {
 }
 
 func (f *ImageFlagGroup) Flags() []*Flag {
-	return []*Flag{f.ImageNameFlag, f.ImageArchitecturesFlag, f.ImageVersionFlag}
+	return []*Flag{f.ImageNameFlag, f.ImageArchitecturesFlag, f.ImageVersionFlag, f.VersionStrategyFlag}
 }
 
-func (f *ImageFlagGroup) ToOptions() ImageOptions {
-	sanitizedVersion, err := evaluateValue(getString(f.ImageVersionFlag))
-	if err != nil {
-		log.Errorf("%v: the version will not be used as expressed", err)
-	}
-
+func (f *ImageFlagGroup) ToOptions() (ImageOptions, error) {
 	opts := ImageOptions{
 		Name:                   getString(f.ImageNameFlag),
 		SupportedArchitectures: getStringSlice(f.ImageArchitecturesFlag),
-		Version:                sanitizedVersion,
+		Version:                getString(f.ImageVersionFlag),
+		VersionStrategy:        getString(f.VersionStrategyFlag),
+	}
+
+	// a version that is defined is used as it is
+	if opts.Version == "" && opts.VersionStrategy != "" {
+		version, err := git.Version(context.TODO(), opts.VersionStrategy)
+		if err != nil {
+			return ImageOptions{}, errors.Wrapf(err, "deriving the version with the %s strategy", opts.VersionStrategy)
+		}
+		opts.Version = version
 	}
 
-	return opts
+	return opts, nil
 }
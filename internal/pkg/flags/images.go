@@ -0,0 +1,149 @@
+package flags
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// ImagesConfigName is the key of the list of images in the config file. Each entry has its own
+// image, build, tag and manifest sections, which are merged over the top-level sections.
+const ImagesConfigName = "images"
+
+// ImageSections are the sections an entry of the images list may set
+var ImageSections = []string{"image", "build", "tag", "manifest"}
+
+// ToImageOptions returns the options of each entry of the images list, selected by their image
+// names. Every entry is selected when no names are given, and the top-level options are returned
+// when the config file has no images list. The flags and environment variables take precedence
+// over the values of an entry.
+func ToImageOptions(globalFlags *GlobalFlagGroup, names []string, f ...FlagGroup) ([]*Options, error) {
+	entries, err := imageEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		if len(names) > 0 {
+			return nil, errors.Errorf("no images are defined in the config file, unable to select %s", strings.Join(names, ", "))
+		}
+		opts, err := ToOptions(globalFlags, f...)
+		if err != nil {
+			return nil, err
+		}
+		return []*Options{opts}, nil
+	}
+
+	var all []*Options
+	var available []string
+	for i, entry := range entries {
+		opts, err := withSettings(newEntrySettings(entry), func() (*Options, error) {
+			return ToOptions(globalFlags, f...)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s[%d]", ImagesConfigName, i)
+		}
+
+		if opts.Image.Name == "" {
+			return nil, errors.Errorf("%s[%d]: the image name is required", ImagesConfigName, i)
+		}
+		if slices.Contains(available, opts.Image.Name) {
+			return nil, errors.Errorf("%s[%d]: the image %s is defined more than once", ImagesConfigName, i, opts.Image.Name)
+		}
+
+		available = append(available, opts.Image.Name)
+		all = append(all, opts)
+	}
+
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	var selected []*Options
+	for _, name := range names {
+		found := false
+		for _, opts := range all {
+			if opts.Image.Name == name {
+				selected = append(selected, opts)
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("the image %s is not defined in the config file (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+
+	return selected, nil
+}
+
+// imageEntries returns the entries of the images list of the config file
+func imageEntries() ([]map[string]interface{}, error) {
+	value := viper.Get(ImagesConfigName)
+	if value == nil {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s must be a list", ImagesConfigName)
+	}
+
+	var entries []map[string]interface{}
+	for i, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s[%d] must be a map", ImagesConfigName, i)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// newEntrySettings returns the settings of an entry of the images list, which are the top-level
+// settings with the sections of the entry merged over them
+func newEntrySettings(entry map[string]interface{}) *viper.Viper {
+	v := viper.New()
+	v.MergeConfigMap(viper.AllSettings())
+
+	for _, section := range ImageSections {
+		if value, ok := entry[section]; ok {
+			setEntryValue(v, section, value)
+		}
+	}
+
+	return v
+}
+
+// setEntryValue sets each value of the entry that is not set by a flag or environment variable,
+// maps are merged key by key so the values of the entry only replace the values they set
+func setEntryValue(v *viper.Viper, key string, value interface{}) {
+	if values, ok := value.(map[string]interface{}); ok {
+		for name, value := range values {
+			setEntryValue(v, fmt.Sprintf("%s.%s", key, strings.ToLower(name)), value)
+		}
+		return
+	}
+
+	if isOverridden(key) {
+		return
+	}
+	v.Set(key, value)
+}
+
+// isOverridden returns true when the key is set by a command line flag or an environment variable
+func isOverridden(key string) bool {
+	return FlagChanged(key) || LookupEnv(key) != ""
+}
+
+// withSettings returns the options read from the settings instead of the top-level settings
+func withSettings(v *viper.Viper, toOptions func() (*Options, error)) (*Options, error) {
+	previous := entrySettings
+	entrySettings = v
+	defer func() { entrySettings = previous }()
+
+	return toOptions()
+}
@@ -0,0 +1,155 @@
+package flags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// readImagesConfig loads the config and binds the build and image flags to a command parsing the args
+func readImagesConfig(t *testing.T, config string, args ...string) (*BuildFlagGroup, *ImageFlagGroup) {
+	t.Cleanup(viper.Reset)
+
+	buildFlags := NewBuildFlagsGroup()
+	imageFlags := NewImageFlagsGroup()
+
+	cmd := &cobra.Command{Use: "build"}
+	AddFlags(cmd, buildFlags, imageFlags)
+	Bind(cmd, buildFlags)
+	Bind(cmd, imageFlags)
+
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	return buildFlags, imageFlags
+}
+
+func TestToImageOptions(t *testing.T) {
+	config := `
+image:
+  version: 1.0.0
+  supported-architectures: [amd64, arm64]
+build:
+  file: Dockerfile
+  context: .
+  push: false
+images:
+  - image:
+      name: api
+    build:
+      file: api/Dockerfile
+  - image:
+      name: web
+      supported-architectures: [amd64]
+    build:
+      context: web
+      push: false
+`
+	buildFlags, imageFlags := readImagesConfig(t, config, "--push")
+
+	imagesOpts, err := ToImageOptions(NewGlobalFlagGroup(), nil, buildFlags, imageFlags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imagesOpts) != 2 {
+		t.Fatalf("expected 2 images, got %v", len(imagesOpts))
+	}
+
+	testCases := []struct {
+		expectedImage   ImageOptions
+		expectedFile    string
+		expectedContext string
+	}{
+		{
+			expectedImage:   ImageOptions{Name: "api", Version: "1.0.0", SupportedArchitectures: []string{"amd64", "arm64"}},
+			expectedFile:    "api/Dockerfile",
+			expectedContext: ".",
+		},
+		{
+			expectedImage:   ImageOptions{Name: "web", Version: "1.0.0", SupportedArchitectures: []string{"amd64"}},
+			expectedFile:    "Dockerfile",
+			expectedContext: "web",
+		},
+	}
+	for i, tc := range testCases {
+		opts := imagesOpts[i]
+		t.Run(tc.expectedImage.Name, func(t *testing.T) {
+			if !reflect.DeepEqual(opts.Image, tc.expectedImage) {
+				t.Errorf("expected %+v, got %+v", tc.expectedImage, opts.Image)
+			}
+			if opts.Build.File != tc.expectedFile {
+				t.Errorf("expected file %v, got %v", tc.expectedFile, opts.Build.File)
+			}
+			if opts.Build.Context != tc.expectedContext {
+				t.Errorf("expected context %v, got %v", tc.expectedContext, opts.Build.Context)
+			}
+			// the flags take precedence over the entries
+			if !opts.Build.Push {
+				t.Error("expected the --push flag to be used")
+			}
+		})
+	}
+
+	// the top-level options are left unchanged
+	if opts, err := ToOptions(NewGlobalFlagGroup(), buildFlags, imageFlags); err != nil || opts.Build.File != "Dockerfile" || opts.Image.Name != "" {
+		t.Errorf("expected the top-level options, got %+v", opts)
+	}
+
+	selected, err := ToImageOptions(NewGlobalFlagGroup(), []string{"web"}, buildFlags, imageFlags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 1 || selected[0].Image.Name != "web" {
+		t.Errorf("expected the web image to be selected, got %v", selected)
+	}
+
+	if _, err := ToImageOptions(NewGlobalFlagGroup(), []string{"worker"}, buildFlags, imageFlags); err == nil {
+		t.Error("expected an error for an unknown image")
+	}
+}
+
+func TestToImageOptions_withoutImages(t *testing.T) {
+	buildFlags, imageFlags := readImagesConfig(t, "image:\n  name: app\n")
+
+	imagesOpts, err := ToImageOptions(NewGlobalFlagGroup(), nil, buildFlags, imageFlags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imagesOpts) != 1 || imagesOpts[0].Image.Name != "app" {
+		t.Errorf("expected the top-level image, got %v", imagesOpts)
+	}
+
+	if _, err := ToImageOptions(NewGlobalFlagGroup(), []string{"app"}, buildFlags, imageFlags); err == nil {
+		t.Error("expected an error when selecting an image without an images list")
+	}
+}
+
+func TestToImageOptions_invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+	}{
+		{name: "missing name", config: "images:\n  - build:\n      file: Dockerfile\n"},
+		{name: "duplicate name", config: "image:\n  name: app\nimages:\n  - build:\n      file: a\n  - build:\n      file: b\n"},
+		{name: "not a list", config: "images: app\n"},
+		{name: "unknown version strategy", config: "image:\n  name: app\n  version-strategy: latest\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildFlags, imageFlags := readImagesConfig(t, tc.config)
+
+			if _, err := ToImageOptions(NewGlobalFlagGroup(), nil, buildFlags, imageFlags); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
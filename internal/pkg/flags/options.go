@@ -1,20 +1,50 @@
 package flags
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 const DefaultArchOption = "prepend"
 
+// redactedValue replaces sensitive values when options are formatted
+const redactedValue = "[REDACTED]"
+
+// credentialProviderEnv is the key of the environment of a credential provider, which may hold
+// the secrets it authenticates with (i.e. registry.credential-provider.env)
+const credentialProviderEnv = "credential-provider.env"
+
 type Options struct {
 	Global   GlobalOptions
 	Build    BuildOptions
+	Config   ConfigOptions
 	Image    ImageOptions
 	Manifest ManifestOptions
+	Promote  PromoteOptions
+	Prune    PruneOptions
 	Tag      TagOptions
 	Version  VersionOptions
 }
 
+// String returns the options with all of the credentials redacted
+func (o Options) String() string {
+	// use a type without the String and Format methods to prevent recursion
+	type options Options
+	return fmt.Sprintf("%+v", options(o))
+}
+
+// Format redacts the credentials regardless of the verb used to format the options
+func (o Options) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, o.String())
+}
+
 type GlobalOptions struct {
 	ConfigFile string
+	Profiles   []string
 	Driver     DriverOptions
 	Registry   RegistryOptions
+	Registries []RegistryOptions
 	Debug      bool
 	DryRun     bool
 	Official   bool
@@ -30,6 +60,45 @@ type BuildOptions struct {
 	Push      bool
 	Pull      bool
 	NoCache   bool
+	Target    string
+	Labels    []string
+	Platform  string
+	Network   string
+	AddHosts  []string
+	ShmSize   string
+	Ulimits   []string
+	Ssh       []string
+	Secrets   []string
+	CacheFrom []string
+	CacheTo   []string
+	OciLabels OciLabelOptions
+}
+
+type OciLabelOptions struct {
+	Enabled bool
+	Exclude []string
+}
+
+// String returns the build options with the secret sources redacted
+func (o BuildOptions) String() string {
+	// use a type without the String method to prevent recursion
+	type buildOptions BuildOptions
+	redacted := buildOptions(o)
+	redacted.Secrets = redactSecrets(o.Secrets)
+	return fmt.Sprintf("%+v", redacted)
+}
+
+type ConfigOptions struct {
+	Init ConfigInitOptions
+}
+
+type ConfigInitOptions struct {
+	Name          string
+	File          string
+	Architectures []string
+	Output        string
+	Force         bool
+	Interactive   bool
 }
 
 type DriverOptions struct {
@@ -40,6 +109,7 @@ type ImageOptions struct {
 	Name                   string
 	SupportedArchitectures []string
 	Version                string
+	VersionStrategy        string
 }
 
 type ManifestOptions struct {
@@ -57,20 +127,76 @@ type ManifestPushOptions struct {
 	Purge bool
 }
 
+type PromoteOptions struct {
+	Source CredentialOptions
+	Target CredentialOptions
+}
+
+type CredentialOptions struct {
+	Username string
+	Password string
+}
+
+// String returns the credential options with the password redacted
+func (o CredentialOptions) String() string {
+	type credentialOptions CredentialOptions
+	redacted := credentialOptions(o)
+	redacted.Password = redact(o.Password)
+	return fmt.Sprintf("%+v", redacted)
+}
+
 type RegistryOptions struct {
-	Url       string
-	Namespace string
-	Username  string
-	Password  string
+	Url           string `mapstructure:"url"`
+	Namespace     string `mapstructure:"namespace"`
+	Username      string `mapstructure:"user"`
+	Password      string `mapstructure:"password"`
+	PasswordFile  string `mapstructure:"password-file"`
+	PasswordStdin bool   `mapstructure:"password-stdin"`
+	Insecure      bool   `mapstructure:"insecure"`
+	CaFile        string `mapstructure:"ca-file"`
+	ClientCert    string `mapstructure:"client-cert"`
+	ClientKey     string `mapstructure:"client-key"`
+	// RateLimitWait is the longest time waited for the rate limit of the registry to reset
+	RateLimitWait time.Duration `mapstructure:"rate-limit-wait"`
+	// CredentialProvider is an executable that prints the credentials of the registry
+	CredentialProvider CredentialProviderOptions `mapstructure:"credential-provider"`
+}
+
+type CredentialProviderOptions struct {
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+	Env     []string `mapstructure:"env"`
+}
+
+// String returns the registry options with the password and the environment of the credential provider redacted
+func (o RegistryOptions) String() string {
+	type registryOptions RegistryOptions
+	redacted := registryOptions(o)
+	redacted.Password = redact(o.Password)
+	redacted.CredentialProvider.Env = redactEnv(o.CredentialProvider.Env)
+	return fmt.Sprintf("%+v", redacted)
+}
+
+type PruneOptions struct {
+	KeepLast  int
+	Keep      []string
+	OlderThan string
+	Apply     bool
 }
 
 type TagOptions struct {
-	Tags []string
-	Push bool
+	Tags   []string
+	Push   bool
+	Remote bool
 }
 
 type VersionOptions struct {
 	Short bool
+	Next  VersionNextOptions
+}
+
+type VersionNextOptions struct {
+	Strategy string
 }
 
 type Git struct {
@@ -79,9 +205,88 @@ type Git struct {
 	FullCommit  string
 	ShortCommit string
 	Tag         string
+	Remote      string
 }
 
 type Version struct {
 	App    string
 	Config string
 }
+
+// redactSecrets keeps the id of each secret (i.e. id=mysecret,src=/local/secret)
+// and replaces everything else with a redacted value
+func redactSecrets(secrets []string) []string {
+	var redacted []string
+	for _, secret := range secrets {
+		value := redactedValue
+		for _, field := range strings.Split(secret, ",") {
+			if strings.HasPrefix(field, "id=") {
+				value = fmt.Sprintf("%s,%s", field, redactedValue)
+			}
+		}
+		redacted = append(redacted, value)
+	}
+	return redacted
+}
+
+// redactEnv keeps the name of each environment variable (i.e. AWS_SECRET_ACCESS_KEY=secret)
+// and replaces its value with a redacted value
+func redactEnv(env []string) []string {
+	var redacted []string
+	for _, variable := range env {
+		name, _, _ := strings.Cut(variable, "=")
+		redacted = append(redacted, fmt.Sprintf("%s=%s", name, redactedValue))
+	}
+	return redacted
+}
+
+// redact hides a sensitive value, an empty value is kept to show it has not been set
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// RedactValue hides the sensitive parts of the value of a config name, the passwords and the
+// environment of the credential providers are redacted at any depth (i.e. registries[0].password)
+// and the sources of the build secrets
+func RedactValue(configName string, value interface{}) interface{} {
+	name := configName[strings.LastIndex(configName, ".")+1:]
+	isProviderEnv := strings.HasSuffix(configName, credentialProviderEnv)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			redacted[key] = RedactValue(configName+"."+key, value)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			redacted = append(redacted, RedactValue(configName, value))
+		}
+		return redacted
+	case []string:
+		if configName == SecretsFlag.ConfigName {
+			return redactSecrets(v)
+		}
+		if isProviderEnv {
+			return redactEnv(v)
+		}
+		return v
+	case string:
+		if name == "password" {
+			return redact(v)
+		}
+		if isProviderEnv {
+			return redactEnv([]string{v})[0]
+		}
+		if configName == SecretsFlag.ConfigName && v != "" {
+			return redactSecrets([]string{v})[0]
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,93 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var sensitiveOpts = &Options{
+	Global: GlobalOptions{
+		Registry: RegistryOptions{
+			Url:      "docker.io",
+			Username: "user",
+			Password: "registry-password",
+			CredentialProvider: CredentialProviderOptions{
+				Command: "ecr-credential-provider",
+				Env:     []string{"AWS_SECRET_ACCESS_KEY=provider-secret"},
+			},
+		},
+		Registries: []RegistryOptions{
+			{Url: "ghcr.io", Username: "user", Password: "mirror-password"},
+		},
+	},
+	Build: BuildOptions{
+		Secrets: []string{"id=mysecret,src=/local/secret"},
+	},
+	Promote: PromoteOptions{
+		Source: CredentialOptions{Username: "source", Password: "source-password"},
+		Target: CredentialOptions{Username: "target", Password: "target-password"},
+	},
+}
+
+func TestOptions_redacted(t *testing.T) {
+	sensitiveValues := []string{"registry-password", "mirror-password", "source-password", "target-password", "/local/secret", "provider-secret"}
+
+	testCases := []struct {
+		name   string
+		format string
+		value  interface{}
+	}{
+		{name: "%v pointer", format: "%v", value: sensitiveOpts},
+		{name: "%+v pointer", format: "%+v", value: sensitiveOpts},
+		{name: "%#v pointer", format: "%#v", value: sensitiveOpts},
+		{name: "%s value", format: "%s", value: *sensitiveOpts},
+		{name: "%+v registry", format: "%+v", value: sensitiveOpts.Global},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := fmt.Sprintf(tc.format, tc.value)
+
+			for _, value := range sensitiveValues {
+				if strings.Contains(output, value) {
+					t.Errorf("expected %v to be redacted, got %v", value, output)
+				}
+			}
+
+			if !strings.Contains(output, "user") {
+				t.Errorf("expected the username to be shown, got %v", output)
+			}
+		})
+	}
+}
+
+func TestOptions_emptyPasswordNotRedacted(t *testing.T) {
+	output := fmt.Sprintf("%+v", RegistryOptions{Username: "user"})
+
+	if strings.Contains(output, redactedValue) {
+		t.Errorf("expected an empty password to be shown as empty, got %v", output)
+	}
+}
+
+func TestRedactValue(t *testing.T) {
+	testCases := []struct {
+		configName string
+		value      interface{}
+		expected   string
+	}{
+		{configName: "registry.password", value: "hunter2", expected: "[REDACTED]"},
+		{configName: "registry.password", value: "", expected: ""},
+		{configName: "registry.user", value: "robot", expected: "robot"},
+		{configName: "build.secrets", value: []string{"id=mysecret,src=/local/secret"}, expected: "[id=mysecret,[REDACTED]]"},
+		{configName: "registries", value: []interface{}{map[string]interface{}{"url": "ghcr.io", "password": "hunter2"}}, expected: "[map[password:[REDACTED] url:ghcr.io]]"},
+		{configName: "registry.credential-provider.env", value: []interface{}{"AWS_PROFILE=ci", "AWS_SECRET_ACCESS_KEY=secret"}, expected: "[AWS_PROFILE=[REDACTED] AWS_SECRET_ACCESS_KEY=[REDACTED]]"},
+		{configName: "registries", value: []interface{}{map[string]interface{}{"credential-provider": map[string]interface{}{"env": []string{"TOKEN=secret"}}}}, expected: "[map[credential-provider:map[env:[TOKEN=[REDACTED]]]]]"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.configName, func(t *testing.T) {
+			if actual := fmt.Sprintf("%v", RedactValue(tc.configName, tc.value)); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
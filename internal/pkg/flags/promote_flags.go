@@ -0,0 +1,67 @@
+package flags
+
+var (
+	PromoteSourceUsernameFlag = Flag{
+		Name:       "source-registry-user",
+		ConfigName: "promote.source.user",
+		Value:      "",
+		Usage:      "The username credential for the source registry (defaults to the registry credentials for the configured registry)",
+	}
+	PromoteSourcePasswordFlag = Flag{
+		Name:       "source-registry-password",
+		ConfigName: "promote.source.password",
+		Value:      "",
+		Usage:      "The password for the source registry read from env:VAR or file:PATH (defaults to the registry credentials for the configured registry)",
+	}
+	PromoteTargetUsernameFlag = Flag{
+		Name:       "target-registry-user",
+		ConfigName: "promote.target.user",
+		Value:      "",
+		Usage:      "The username credential for the target registry (defaults to the registry credentials for the configured registry)",
+	}
+	PromoteTargetPasswordFlag = Flag{
+		Name:       "target-registry-password",
+		ConfigName: "promote.target.password",
+		Value:      "",
+		Usage:      "The password for the target registry read from env:VAR or file:PATH (defaults to the registry credentials for the configured registry)",
+	}
+)
+
+type PromoteFlagGroup struct {
+	SourceUsernameFlag *Flag
+	SourcePasswordFlag *Flag
+	TargetUsernameFlag *Flag
+	TargetPasswordFlag *Flag
+}
+
+func NewPromoteFlagGroup() *PromoteFlagGroup {
+	return &PromoteFlagGroup{
+		SourceUsernameFlag: &PromoteSourceUsernameFlag,
+		SourcePasswordFlag: &PromoteSourcePasswordFlag,
+		TargetUsernameFlag: &PromoteTargetUsernameFlag,
+		TargetPasswordFlag: &PromoteTargetPasswordFlag,
+	}
+}
+
+func (f *PromoteFlagGroup) Name() string {
+	return "Promote"
+}
+
+func (f *PromoteFlagGroup) Flags() []*Flag {
+	return []*Flag{f.SourceUsernameFlag, f.SourcePasswordFlag, f.TargetUsernameFlag, f.TargetPasswordFlag}
+}
+
+func (f *PromoteFlagGroup) ToOptions() PromoteOptions {
+	opts := PromoteOptions{
+		Source: CredentialOptions{
+			Username: getString(f.SourceUsernameFlag),
+			Password: getString(f.SourcePasswordFlag),
+		},
+		Target: CredentialOptions{
+			Username: getString(f.TargetUsernameFlag),
+			Password: getString(f.TargetPasswordFlag),
+		},
+	}
+
+	return opts
+}
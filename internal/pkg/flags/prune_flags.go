@@ -0,0 +1,63 @@
+package flags
+
+var (
+	PruneKeepLastFlag = Flag{
+		Name:       "keep-last",
+		ConfigName: "prune.keep-last",
+		Value:      10,
+		Usage:      "Keep the most recent number of semantic version tags, older versions are deleted (0 disables the policy)",
+	}
+	PruneKeepFlag = Flag{
+		Name:       "keep",
+		ConfigName: "prune.keep",
+		Value:      StringArray{"latest"},
+		Usage:      "Regular expressions of the tags that are never deleted, a pattern must match the whole tag",
+	}
+	PruneOlderThanFlag = Flag{
+		Name:       "older-than",
+		ConfigName: "prune.older-than",
+		Value:      "",
+		Usage:      "Delete the tags of images created before this age (i.e. 72h, 30d), the last versions kept are never deleted",
+	}
+	PruneApplyFlag = Flag{
+		Name:       "apply",
+		ConfigName: "prune.apply",
+		Value:      false,
+		Usage:      "Delete the tags, otherwise only the plan is shown",
+	}
+)
+
+type PruneFlagGroup struct {
+	KeepLastFlag  *Flag
+	KeepFlag      *Flag
+	OlderThanFlag *Flag
+	ApplyFlag     *Flag
+}
+
+func NewPruneFlagGroup() *PruneFlagGroup {
+	return &PruneFlagGroup{
+		KeepLastFlag:  &PruneKeepLastFlag,
+		KeepFlag:      &PruneKeepFlag,
+		OlderThanFlag: &PruneOlderThanFlag,
+		ApplyFlag:     &PruneApplyFlag,
+	}
+}
+
+func (f *PruneFlagGroup) Name() string {
+	return "Prune"
+}
+
+func (f *PruneFlagGroup) Flags() []*Flag {
+	return []*Flag{f.KeepLastFlag, f.KeepFlag, f.OlderThanFlag, f.ApplyFlag}
+}
+
+func (f *PruneFlagGroup) ToOptions() PruneOptions {
+	opts := PruneOptions{
+		KeepLast:  getInt(f.KeepLastFlag),
+		Keep:      getStringArray(f.KeepFlag),
+		OlderThan: getString(f.OlderThanFlag),
+		Apply:     getBool(f.ApplyFlag),
+	}
+
+	return opts
+}
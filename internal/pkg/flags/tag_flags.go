@@ -13,17 +13,25 @@ var (
 		Value:      false,
 		Usage:      "Push the tagged images to a container registry",
 	}
+	TagRemoteFlag = Flag{
+		Name:       "remote",
+		ConfigName: "tag.remote",
+		Value:      false,
+		Usage:      "Create the tags directly in the registry without pulling the images",
+	}
 )
 
 type TagFlagGroup struct {
-	TagTagsFlag *Flag
-	TagPushFlag *Flag
+	TagTagsFlag   *Flag
+	TagPushFlag   *Flag
+	TagRemoteFlag *Flag
 }
 
 func NewTagFlagsGroup() *TagFlagGroup {
 	return &TagFlagGroup{
-		TagTagsFlag: &TagTagsFlag,
-		TagPushFlag: &TagPushFlag,
+		TagTagsFlag:   &TagTagsFlag,
+		TagPushFlag:   &TagPushFlag,
+		TagRemoteFlag: &TagRemoteFlag,
 	}
 }
 
@@ -32,13 +40,14 @@ func (f *TagFlagGroup) Name() string {
 }
 
 func (f *TagFlagGroup) Flags() []*Flag {
-	return []*Flag{f.TagTagsFlag, f.TagPushFlag}
+	return []*Flag{f.TagTagsFlag, f.TagPushFlag, f.TagRemoteFlag}
 }
 
 func (f *TagFlagGroup) ToOptions() TagOptions {
 	opts := TagOptions{
-		Tags: getStringSlice(f.TagTagsFlag),
-		Push: getBool(f.TagPushFlag),
+		Tags:   getStringSlice(f.TagTagsFlag),
+		Push:   getBool(f.TagPushFlag),
+		Remote: getBool(f.TagRemoteFlag),
 	}
 
 	return opts
@@ -34,3 +34,31 @@ func (f *VersionFlagGroup) ToOptions() VersionOptions {
 
 	return opts
 }
+
+// VersionNextFlagGroup only reads the version strategy, the version is not derived when the options
+// are read since the command derives it with the strategy it previews
+type VersionNextFlagGroup struct {
+	StrategyFlag *Flag
+}
+
+func NewVersionNextFlagGroup() *VersionNextFlagGroup {
+	return &VersionNextFlagGroup{
+		StrategyFlag: &ImageVersionStrategyFlag,
+	}
+}
+
+func (f *VersionNextFlagGroup) Name() string {
+	return "Version Next"
+}
+
+func (f *VersionNextFlagGroup) Flags() []*Flag {
+	return []*Flag{f.StrategyFlag}
+}
+
+func (f *VersionNextFlagGroup) ToOptions() VersionNextOptions {
+	opts := VersionNextOptions{
+		Strategy: getString(f.StrategyFlag),
+	}
+
+	return opts
+}
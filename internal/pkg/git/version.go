@@ -0,0 +1,140 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The strategies deriving a version from the git history
+const (
+	// GitDescribe is the most recent tag, followed by the number of commits since and the commit
+	// when the current commit is not tagged (i.e. v1.2.0-3-g1a2b3c4)
+	GitDescribe = "git-describe"
+	// SemverFromTags is the next version after the most recent semver tag, the conventional commits
+	// since the tag choose the part that is incremented (i.e. v1.3.0 after v1.2.0 and a feat commit)
+	SemverFromTags = "semver-from-tags"
+	// CommitCount is the number of commits of the current branch (i.e. 482)
+	CommitCount = "commit-count"
+	// Calver is the date of the current commit followed by the number of commits of that day
+	// (i.e. 2024.03.18.2)
+	Calver = "calver"
+)
+
+// VersionStrategies are the accepted version strategies
+var VersionStrategies = []string{GitDescribe, SemverFromTags, CommitCount, Calver}
+
+// semverTag matches the release tags (i.e. 1.2.0 or v1.2.0), the pre-releases are skipped
+var semverTag = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+// conventionalCommit matches the header of a conventional commit (i.e. feat(api)!: remove the v1 routes)
+var conventionalCommit = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?:`)
+
+// Version derives the version of the current commit with a strategy
+func Version(ctx context.Context, strategy string) (string, error) {
+	switch strategy {
+	case GitDescribe:
+		return Clean(Run(ctx, "describe --tags --always"))
+	case SemverFromTags:
+		return semverFromTags(ctx)
+	case CommitCount:
+		return Clean(Run(ctx, "rev-list --count HEAD"))
+	case Calver:
+		return calver(ctx)
+	}
+	return "", fmt.Errorf("unknown version strategy %q, expected one of %s", strategy, strings.Join(VersionStrategies, ", "))
+}
+
+// semverFromTags increments the most recent semver tag with the commits since, the tag is returned
+// when the current commit is tagged
+func semverFromTags(ctx context.Context) (string, error) {
+	tags, err := CleanAllLines(Run(ctx, "tag --merged HEAD --sort=-v:refname"))
+	if err != nil {
+		return "", err
+	}
+
+	var latest []string
+	for _, tag := range tags {
+		if latest = semverTag.FindStringSubmatch(tag); latest != nil {
+			break
+		}
+	}
+
+	prefix, revisions := "", "HEAD"
+	version := [3]int{0, 0, 0}
+	if latest != nil {
+		prefix, revisions = latest[1], latest[0]+"..HEAD"
+		for i := range version {
+			version[i], _ = strconv.Atoi(latest[i+2])
+		}
+	}
+
+	output, err := RunWithEnv(ctx, []string{}, "log", "--format=%B%x00", revisions)
+	if err != nil {
+		return "", err
+	}
+
+	var messages []string
+	for _, message := range strings.Split(output, "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	if latest != nil && len(messages) == 0 {
+		return latest[0], nil
+	}
+
+	version = nextSemver(version, messages)
+	return fmt.Sprintf("%s%d.%d.%d", prefix, version[0], version[1], version[2]), nil
+}
+
+// nextSemver increments the major version for a breaking change, the minor version for a feature
+// and the patch version otherwise. A breaking change before 1.0.0 increments the minor version.
+func nextSemver(version [3]int, messages []string) [3]int {
+	part := 2
+	for _, message := range messages {
+		matches := conventionalCommit.FindStringSubmatch(message)
+		switch {
+		case matches != nil && matches[2] == "!",
+			strings.Contains(message, "BREAKING CHANGE:"), strings.Contains(message, "BREAKING-CHANGE:"):
+			part = 0
+		case matches != nil && matches[1] == "feat":
+			part = min(part, 1)
+		}
+	}
+
+	if part == 0 && version[0] == 0 {
+		part = 1
+	}
+
+	version[part]++
+	for i := part + 1; i < len(version); i++ {
+		version[i] = 0
+	}
+	return version
+}
+
+// calver returns the date of the current commit with the number of commits made that day
+func calver(ctx context.Context) (string, error) {
+	timestamp, err := Clean(Run(ctx, "log -1 --format=%ct"))
+	if err != nil {
+		return "", err
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit date %q", timestamp)
+	}
+
+	date := time.Unix(seconds, 0).UTC()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	count, err := Clean(RunWithEnv(ctx, []string{}, "rev-list", "--count", "--since="+day.Format(time.RFC3339), "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", date.Format("2006.01.02"), count), nil
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+)
+
+// newRepo creates a git repository in a temporary directory and changes to it, each message is
+// committed and a tag is created for the commits of the tags
+func newRepo(t *testing.T, messages []string, tags map[int]string) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "tugboat")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "tugboat@example.com")
+	}
+
+	ctx := context.Background()
+	if _, err := Run(ctx, "init --quiet"); err != nil {
+		t.Fatal(err)
+	}
+	for i, message := range messages {
+		if _, err := RunWithEnv(ctx, []string{}, "commit", "--allow-empty", "--quiet", "-m", message); err != nil {
+			t.Fatal(err)
+		}
+		if tag, ok := tags[i]; ok {
+			if _, err := Run(ctx, "tag "+tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestVersion_semverFromTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		messages []string
+		tags     map[int]string
+		expected string
+	}{
+		{
+			name:     "no tags",
+			messages: []string{"feat: first feature"},
+			expected: "0.1.0",
+		},
+		{
+			name:     "tagged commit",
+			messages: []string{"feat: first feature", "fix: first fix"},
+			tags:     map[int]string{1: "v1.2.0"},
+			expected: "v1.2.0",
+		},
+		{
+			name:     "fix",
+			messages: []string{"feat: first feature", "fix: first fix", "docs: readme"},
+			tags:     map[int]string{0: "1.2.0"},
+			expected: "1.2.1",
+		},
+		{
+			name:     "feature",
+			messages: []string{"initial", "fix(api): first fix", "feat(api): first feature"},
+			tags:     map[int]string{0: "v1.2.3"},
+			expected: "v1.3.0",
+		},
+		{
+			name:     "breaking change",
+			messages: []string{"initial", "feat!: remove the v1 routes"},
+			tags:     map[int]string{0: "v1.2.3"},
+			expected: "v2.0.0",
+		},
+		{
+			name:     "breaking change in the footer",
+			messages: []string{"initial", "refactor: routes\n\nBREAKING CHANGE: the v1 routes are removed"},
+			tags:     map[int]string{0: "v0.4.1"},
+			expected: "v0.5.0",
+		},
+		{
+			name:     "latest release tag",
+			messages: []string{"initial", "fix: first fix", "fix: second fix"},
+			tags:     map[int]string{0: "v1.9.0", 1: "v1.10.0", 2: "v1.11.0-rc.1"},
+			expected: "v1.10.1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newRepo(t, tc.messages, tc.tags)
+
+			actual, err := Version(context.Background(), SemverFromTags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	newRepo(t, []string{"initial", "fix: first fix", "feat: first feature"}, map[int]string{0: "v1.0.0"})
+
+	testCases := []struct {
+		strategy string
+		expected string
+	}{
+		{strategy: GitDescribe, expected: `^v1\.0\.0-2-g[0-9a-f]+$`},
+		{strategy: CommitCount, expected: `^3$`},
+		{strategy: Calver, expected: `^\d{4}\.\d{2}\.\d{2}\.3$`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			actual, err := Version(context.Background(), tc.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !regexp.MustCompile(tc.expected).MatchString(actual) {
+				t.Errorf("expected %v to match %v", actual, tc.expected)
+			}
+		})
+	}
+
+	if _, err := Version(context.Background(), "latest"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,163 @@
+package interpolate
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"tugboat/internal/pkg/git"
+
+	"github.com/pkg/errors"
+)
+
+// gitLookups are the values of the git function (i.e. ${git:short-commit})
+var gitLookups = map[string]func(ctx context.Context) (string, error){
+	"branch": func(ctx context.Context) (string, error) {
+		return git.Branch(ctx), nil
+	},
+	"commit": func(ctx context.Context) (string, error) {
+		return git.Clean(git.Run(ctx, "rev-parse HEAD"))
+	},
+	"short-commit": func(ctx context.Context) (string, error) {
+		return git.Clean(git.Run(ctx, "log -1 --pretty=%h"))
+	},
+	"tag": func(ctx context.Context) (string, error) {
+		return git.ExactTag(ctx), nil
+	},
+	"describe": func(ctx context.Context) (string, error) {
+		return git.Clean(git.Run(ctx, "describe --tags --always"))
+	},
+}
+
+// Expand replaces the variables of a value without running a shell. The supported expressions are
+//
+//	$VAR or ${VAR}     the value of an environment variable, empty when it is not set
+//	${VAR:-default}    the default when the variable is not set or empty
+//	${VAR:?message}    an error with the message when the variable is not set or empty
+//	${file:PATH}       the content of a file, without the surrounding whitespace
+//	${git:NAME}        a git value, one of branch, commit, short-commit, tag or describe
+//	$$                 a literal $
+func Expand(ctx context.Context, value string) (string, error) {
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+
+		next := value[i+1]
+		switch {
+		case next == '$':
+			builder.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(value, i+1)
+			if end < 0 {
+				return "", errors.Errorf("missing the closing brace in %q", value)
+			}
+			expanded, err := expandExpression(ctx, value[i+2:end])
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(expanded)
+			i = end
+		case next == '(':
+			return "", errors.Errorf("commands are not run in %q, use ${file:PATH} to read a file or ${git:NAME} for a git value", value)
+		case isNameStart(next):
+			end := nameEnd(value, i+1)
+			builder.WriteString(os.Getenv(value[i+1 : end]))
+			i = end - 1
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// expandExpression expands the expression between the braces of ${...}
+func expandExpression(ctx context.Context, expression string) (string, error) {
+	name := expression[:nameEnd(expression, 0)]
+	if name == "" || !isNameStart(name[0]) {
+		return "", errors.Errorf("invalid expression ${%s}", expression)
+	}
+	operation := expression[len(name):]
+
+	switch {
+	case operation == "":
+		return os.Getenv(name), nil
+	case strings.HasPrefix(operation, ":-"):
+		if value := os.Getenv(name); value != "" {
+			return value, nil
+		}
+		return Expand(ctx, operation[2:])
+	case strings.HasPrefix(operation, ":?"):
+		if value := os.Getenv(name); value != "" {
+			return value, nil
+		}
+		message := operation[2:]
+		if message == "" {
+			message = "is not set"
+		}
+		return "", errors.Errorf("%s: %s", name, message)
+	case name == "file" && strings.HasPrefix(operation, ":"):
+		content, err := os.ReadFile(operation[1:])
+		if err != nil {
+			return "", errors.Wrap(err, "reading the file")
+		}
+		return strings.TrimSpace(string(content)), nil
+	case name == "git" && strings.HasPrefix(operation, ":"):
+		lookup, ok := gitLookups[operation[1:]]
+		if !ok {
+			return "", errors.Errorf("unknown git value %q, expected one of %s", operation[1:], strings.Join(gitNames(), ", "))
+		}
+		value, err := lookup(ctx)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading the git %s", operation[1:])
+		}
+		return value, nil
+	}
+
+	return "", errors.Errorf("invalid expression ${%s}", expression)
+}
+
+// closingBrace returns the index of the brace closing the one at start, the braces of the nested
+// expressions (i.e. ${A:-${B}}) are skipped. -1 is returned when it is not closed.
+func closingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// nameEnd returns the index after the variable name starting at start
+func nameEnd(value string, start int) int {
+	end := start
+	for end < len(value) && (isNameStart(value[end]) || (value[end] >= '0' && value[end] <= '9')) {
+		end++
+	}
+	return end
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func gitNames() []string {
+	var names []string
+	for name := range gitLookups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,86 @@
+package interpolate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("VERSION", "1.2.0")
+	t.Setenv("EMPTY", "")
+
+	file := filepath.Join(t.TempDir(), "VERSION")
+	if err := os.WriteFile(file, []byte("2.0.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "no variables", value: "{{.ImageName}}:latest", expected: "{{.ImageName}}:latest"},
+		{name: "variable", value: "$VERSION", expected: "1.2.0"},
+		{name: "braces", value: "v${VERSION}-rc", expected: "v1.2.0-rc"},
+		{name: "variable in text", value: "app-$VERSION.tar", expected: "app-1.2.0.tar"},
+		{name: "unset variable", value: "app-$MISSING", expected: "app-"},
+		{name: "default", value: "${MISSING:-dev}", expected: "dev"},
+		{name: "default of an empty variable", value: "${EMPTY:-dev}", expected: "dev"},
+		{name: "default is not used", value: "${VERSION:-dev}", expected: "1.2.0"},
+		{name: "nested default", value: "${MISSING:-${VERSION}}", expected: "1.2.0"},
+		{name: "required", value: "${VERSION:?the version is required}", expected: "1.2.0"},
+		{name: "file", value: "${file:" + file + "}", expected: "2.0.0"},
+		{name: "escaped", value: "pa$$word", expected: "pa$word"},
+		{name: "trailing dollar", value: "price$", expected: "price$"},
+		{name: "not a variable", value: "$1", expected: "$1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Expand(context.Background(), tc.value)
+			if err != nil {
+				t.Fatalf("An unexpected error occurred: %v", err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpand_errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "required", value: "${MISSING:?the version is required}", expected: "MISSING: the version is required"},
+		{name: "required without a message", value: "${MISSING:?}", expected: "MISSING: is not set"},
+		{name: "command", value: "$(cat VERSION)", expected: `commands are not run in "$(cat VERSION)", use ${file:PATH} to read a file or ${git:NAME} for a git value`},
+		{name: "command in braces", value: "${$(whoami)}", expected: "invalid expression ${$(whoami)}"},
+		{name: "unclosed", value: "${VERSION", expected: `missing the closing brace in "${VERSION"`},
+		{name: "unknown git value", value: "${git:author}", expected: `unknown git value "author", expected one of branch, commit, describe, short-commit, tag`},
+		{name: "missing file", value: "${file:missing/VERSION}", expected: "reading the file: open missing/VERSION: no such file or directory"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Expand(context.Background(), tc.value)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("expected the error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestExpand_git(t *testing.T) {
+	commit, err := Expand(context.Background(), "${git:commit}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commit) != 40 {
+		t.Errorf("expected the full commit, got %v", commit)
+	}
+}
@@ -108,6 +108,11 @@ func (r Reference) Remote() string {
 	return r.named.FullName() + r.tag
 }
 
+// IsInsecure returns true when the url uses plain http (i.e. http://localhost:5000)
+func IsInsecure(url string) bool {
+	return strings.HasPrefix(url, "http://")
+}
+
 func clean(url string) string {
 	s := url
 
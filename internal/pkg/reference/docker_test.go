@@ -598,3 +598,21 @@ func runTestCaseWithArchOfficial(t *testing.T, tc testCaseWithArch) {
 		t.Errorf("expected value: '%v'; actual value: '%v'", tc.expected, actual)
 	}
 }
+
+func TestIsInsecure(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "http://localhost:5000", expected: true},
+		{url: "https://registry.example.com", expected: false},
+		{url: "docker.io", expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			if actual := IsInsecure(tc.url); tc.expected != actual {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultTokenExpiry is the lifetime of a token when the response does not include one
+	defaultTokenExpiry = 60 * time.Second
+	// tokenExpiryMargin is how long before it expires a token is renewed
+	tokenExpiryMargin = 10 * time.Second
+)
+
+// challenge is a parsed WWW-Authenticate header (i.e. Bearer realm="...",service="...",scope="...")
+type challenge struct {
+	scheme     string
+	parameters map[string]string
+}
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// authorize returns the value of the Authorization header that satisfies the challenge and how
+// long it is valid for, zero is returned when it does not expire
+func (c *Client) authorize(ctx context.Context, ch *challenge, scopes []string) (string, time.Duration, error) {
+	user, expires, err := c.credentials(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	switch ch.scheme {
+	case "basic":
+		if user == nil {
+			return "", 0, errors.Wrapf(ErrUnauthorized, "%s requires credentials", c.host)
+		}
+
+		// the authorization is renewed with the credentials
+		var expiresIn time.Duration
+		if !expires.IsZero() {
+			expiresIn = max(time.Until(expires), time.Second)
+		}
+		return basicAuthorization(user), expiresIn, nil
+	case "bearer":
+		token, expiresIn, err := c.fetchToken(ctx, ch, user, scopes)
+		if err != nil {
+			return "", 0, err
+		}
+		return "Bearer " + token, expiresIn, nil
+	}
+
+	return "", 0, errors.Wrapf(ErrUnauthorized, "unsupported authentication challenge from %s: %q", c.host, ch.scheme)
+}
+
+// fetchToken requests a bearer token from the authorization service defined in the challenge
+func (c *Client) fetchToken(ctx context.Context, ch *challenge, user *RegistryUser, scopes []string) (string, time.Duration, error) {
+	realm := ch.parameters["realm"]
+	if realm == "" {
+		return "", 0, errors.Errorf("invalid authentication challenge from %s: missing realm", c.host)
+	}
+
+	query := url.Values{}
+	if service := ch.parameters["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if scope := ch.parameters["scope"]; scope != "" {
+		query.Add("scope", scope)
+	}
+	for _, scope := range scopes {
+		if scope != ch.parameters["scope"] {
+			query.Add("scope", scope)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?%s", realm, query.Encode()), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	if user != nil {
+		req.Header.Set("Authorization", basicAuthorization(user))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", 0, errors.Wrapf(ErrUnauthorized, "requesting a token from %s", realm)
+	}
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		return "", 0, err
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", 0, errors.Wrap(err, "decoding the token response")
+	}
+
+	expiresIn := defaultTokenExpiry
+	if token.ExpiresIn > 0 {
+		expiresIn = time.Duration(token.ExpiresIn) * time.Second
+	}
+
+	if token.Token != "" {
+		return token.Token, expiresIn, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, expiresIn, nil
+	}
+
+	return "", 0, errors.Errorf("no token was returned from %s", realm)
+}
+
+// parseChallenge parses a WWW-Authenticate header into its scheme and parameters
+func parseChallenge(header string) *challenge {
+	ch := &challenge{parameters: make(map[string]string)}
+
+	scheme, params, _ := strings.Cut(strings.TrimSpace(header), " ")
+	ch.scheme = strings.ToLower(scheme)
+
+	for params != "" {
+		var key string
+		key, params, _ = strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(params, `"`) {
+			end := strings.Index(params[1:], `"`)
+			if end == -1 {
+				value, params = params[1:], ""
+			} else {
+				value, params = params[1:end+1], params[end+2:]
+			}
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+
+		if key != "" {
+			ch.parameters[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return ch
+}
+
+func basicAuthorization(user *RegistryUser) string {
+	credentials := fmt.Sprintf("%s:%s", user.Name, user.Password)
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// repositoryScope returns the token scope for the given repository and actions (i.e. repository:ns/app:pull)
+func repositoryScope(repository string, actions ...string) string {
+	return fmt.Sprintf("repository:%s:%s", repository, strings.Join(actions, ","))
+}
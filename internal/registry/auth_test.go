@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"testing"
+)
+
+func Test_parseChallenge(t *testing.T) {
+	testCases := []struct {
+		name       string
+		header     string
+		scheme     string
+		parameters map[string]string
+	}{
+		{
+			name:   "bearer",
+			header: `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/ubuntu:pull"`,
+			scheme: "bearer",
+			parameters: map[string]string{
+				"realm":   "https://auth.docker.io/token",
+				"service": "registry.docker.io",
+				"scope":   "repository:library/ubuntu:pull",
+			},
+		},
+		{
+			name:   "scope with multiple actions",
+			header: `Bearer realm="https://auth.example.com/token", scope="repository:ns/app:pull,push"`,
+			scheme: "bearer",
+			parameters: map[string]string{
+				"realm": "https://auth.example.com/token",
+				"scope": "repository:ns/app:pull,push",
+			},
+		},
+		{
+			name:       "basic",
+			header:     `Basic realm="Registry Realm"`,
+			scheme:     "basic",
+			parameters: map[string]string{"realm": "Registry Realm"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := parseChallenge(tc.header)
+
+			if tc.scheme != ch.scheme {
+				t.Errorf("expected scheme %v, got %v", tc.scheme, ch.scheme)
+			}
+
+			if len(tc.parameters) != len(ch.parameters) {
+				t.Errorf("expected parameters %v, got %v", tc.parameters, ch.parameters)
+			}
+
+			for key, expected := range tc.parameters {
+				if ch.parameters[key] != expected {
+					t.Errorf("expected %v=%v, got %v", key, expected, ch.parameters[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// BlobExists returns true if the blob is present in the repository
+func (c *Client) BlobExists(ctx context.Context, repository string, dgst digest.Digest) (bool, error) {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodHead,
+		path:   blobPath(repository, dgst),
+		scopes: []string{repositoryScope(repository, "pull")},
+	})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, checkResponse(resp, http.StatusOK)
+}
+
+// GetBlob returns a reader for the content of the blob, the caller must close the reader
+func (c *Client) GetBlob(ctx context.Context, repository string, dgst digest.Digest) (io.ReadCloser, int64, error) {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   blobPath(repository, dgst),
+		scopes: []string{repositoryScope(repository, "pull")},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, 0, err
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// MountBlob attempts to mount a blob from another repository in the same registry. False is
+// returned when the registry did not mount the blob and it needs to be uploaded instead.
+func (c *Client) MountBlob(ctx context.Context, repository string, fromRepository string, dgst digest.Digest) (bool, error) {
+	query := url.Values{}
+	query.Set("mount", dgst.String())
+	query.Set("from", fromRepository)
+
+	resp, err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/v2/%s/blobs/uploads/?%s", repository, query.Encode()),
+		scopes: []string{
+			repositoryScope(repository, "pull", "push"),
+			repositoryScope(fromRepository, "pull"),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return true, nil
+	case http.StatusAccepted:
+		// the registry started a regular upload session instead of mounting
+		if location := resp.Header.Get("Location"); location != "" {
+			c.cancelUpload(ctx, repository, location)
+		}
+		return false, nil
+	}
+
+	return false, checkResponse(resp, http.StatusCreated, http.StatusAccepted)
+}
+
+// UploadBlob uploads the content of a blob to the repository in a single request
+func (c *Client) UploadBlob(ctx context.Context, repository string, dgst digest.Digest, content io.Reader, size int64) error {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/v2/%s/blobs/uploads/", repository),
+		scopes: []string{repositoryScope(repository, "pull", "push")},
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if err := checkResponse(resp, http.StatusAccepted); err != nil {
+		return err
+	}
+
+	location, err := c.uploadLocation(resp)
+	if err != nil {
+		return err
+	}
+
+	query := location.Query()
+	query.Set("digest", dgst.String())
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, &request{
+		method:  http.MethodPut,
+		path:    location.String(),
+		headers: map[string]string{"Content-Type": "application/octet-stream"},
+		body:    content,
+		size:    size,
+		scopes:  []string{repositoryScope(repository, "pull", "push")},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, http.StatusCreated)
+}
+
+// uploadLocation resolves the upload session url returned by the registry
+func (c *Client) uploadLocation(resp *http.Response) (*url.URL, error) {
+	header := resp.Header.Get("Location")
+	if header == "" {
+		return nil, errors.Errorf("%s did not return an upload location", c.host)
+	}
+
+	location, err := resp.Request.URL.Parse(header)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the upload location")
+	}
+
+	return location, nil
+}
+
+// cancelUpload removes an unused upload session, failures are ignored as the session expires
+func (c *Client) cancelUpload(ctx context.Context, repository string, location string) {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodDelete,
+		path:   location,
+		scopes: []string{repositoryScope(repository, "pull", "push")},
+	})
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+func blobPath(repository string, dgst digest.Digest) string {
+	return fmt.Sprintf("/v2/%s/blobs/%s", repository, dgst)
+}
@@ -0,0 +1,371 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"tugboat/internal/pkg/reference"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// dockerHubHost is the registry host used to reference images in Docker Hub
+	dockerHubHost = "docker.io"
+	// dockerHubEndpoint is the host serving the registry api for Docker Hub
+	dockerHubEndpoint = "registry-1.docker.io"
+)
+
+var ErrUnauthorized = errors.New("registry authentication failed")
+var ErrNotFound = errors.New("not found in the registry")
+
+// Client communicates with a container registry using the distribution (v2) http api
+type Client struct {
+	host       string
+	endpoint   string
+	user       *RegistryUser
+	provider   *CredentialProvider
+	httpClient *http.Client
+	insecure   bool
+	// err is returned by every request when the client could not be configured
+	err error
+	// probe finds whether an insecure registry is served over https or plain http
+	probe sync.Once
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+	// challenge is the last authentication challenge of the registry, without the scope of the request
+	challenge *challenge
+	// rateLimit is the last rate limit reported by the registry
+	rateLimit       *RateLimit
+	rateLimitWarned bool
+	rateLimitWait   time.Duration
+}
+
+// cachedToken is the value of an Authorization header, reused for the same scopes until it expires
+type cachedToken struct {
+	authorization string
+	// expires is zero when the authorization does not expire (i.e. basic auth)
+	expires time.Time
+}
+
+type ClientOptions struct {
+	// User is the credential used to authenticate, anonymous access is used when nil
+	User *RegistryUser
+
+	// CredentialProvider supplies the credentials instead of User, renewing them when they expire
+	CredentialProvider *CredentialProvider
+
+	// HttpClient is used to send the requests, http.DefaultClient is used when nil
+	HttpClient *http.Client
+
+	// TLS secures the connection to the registry, it is ignored when a HttpClient is provided
+	TLS TLSOptions
+
+	// RateLimitWait is the longest time waited for the rate limit of the registry to reset, rate
+	// limited requests fail immediately when zero
+	RateLimitWait time.Duration
+}
+
+// NewClient creates a client for the registry at the given host (i.e. docker.io, localhost:5000).
+// A host starting with http:// is reached over plain http.
+func NewClient(host string, opts *ClientOptions) *Client {
+	if opts == nil {
+		opts = &ClientOptions{}
+	}
+
+	plainHttp := reference.IsInsecure(host)
+	host = strings.TrimSuffix(cleanHost(host), "/")
+
+	c := &Client{
+		host:       host,
+		endpoint:   fmt.Sprintf("https://%s", endpointHost(host)),
+		user:       opts.User,
+		provider:   opts.CredentialProvider,
+		httpClient: opts.HttpClient,
+		insecure:   opts.TLS.Insecure && !plainHttp,
+		tokens:     make(map[string]cachedToken),
+
+		rateLimitWait: opts.RateLimitWait,
+	}
+
+	if plainHttp {
+		c.endpoint = fmt.Sprintf("http://%s", endpointHost(host))
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+		if !opts.TLS.IsDefault() {
+			c.httpClient, c.err = opts.TLS.httpClient()
+		}
+	}
+
+	return c
+}
+
+// Host returns the registry host the client is connected to
+func (c *Client) Host() string {
+	return c.host
+}
+
+// request describes a call to the registry api
+type request struct {
+	method  string
+	path    string
+	headers map[string]string
+	body    io.Reader
+	size    int64
+	// scopes are the token scopes required by the request (i.e. repository:ns/app:pull), the
+	// tokens are cached per set of scopes
+	scopes []string
+}
+
+// do sends the request to the registry, completing an authentication challenge when required
+func (c *Client) do(ctx context.Context, r *request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	req, err := c.newRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeKey := strings.Join(r.scopes, " ")
+	authorization, ok := c.cachedAuthorization(scopeKey)
+	if !ok {
+		// authenticate up front when the registry challenged a previous request
+		if ch := c.lastChallenge(); ch != nil {
+			if authorization, err = c.authenticate(ctx, scopeKey, ch, r.scopes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// complete the challenge and retry the request
+	ch := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	resp.Body.Close()
+	c.setChallenge(ch)
+
+	authorization, err = c.authenticate(ctx, scopeKey, ch, r.scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return nil, errors.Errorf("%s %s: unable to resend the request body after authenticating", r.method, r.path)
+	}
+
+	retry, err := c.newRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", authorization)
+
+	resp, err = c.send(ctx, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, errors.Wrapf(ErrUnauthorized, "%s %s", r.method, r.path)
+	}
+
+	return resp, nil
+}
+
+// Ping checks that the registry supports the v2 api, authenticating when the registry requires it
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/v2/",
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, http.StatusOK)
+}
+
+// authenticate completes the challenge for the scopes and caches the authorization
+func (c *Client) authenticate(ctx context.Context, scopeKey string, ch *challenge, scopes []string) (string, error) {
+	authorization, expiresIn, err := c.authorize(ctx, ch, scopes)
+	if err != nil {
+		return "", err
+	}
+	c.cacheAuthorization(scopeKey, authorization, expiresIn)
+
+	return authorization, nil
+}
+
+// credentials returns the user to authenticate as and when its credentials expire, the expiry is
+// zero when they do not expire
+func (c *Client) credentials(ctx context.Context) (*RegistryUser, time.Time, error) {
+	if c.provider != nil {
+		return c.provider.Credentials(ctx, c.host)
+	}
+	return c.user, time.Time{}, nil
+}
+
+func (c *Client) lastChallenge() *challenge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.challenge
+}
+
+// setChallenge stores the challenge so the following requests are authenticated before they are sent
+func (c *Client) setChallenge(ch *challenge) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parameters := make(map[string]string)
+	for key, value := range ch.parameters {
+		if key != "scope" {
+			parameters[key] = value
+		}
+	}
+	c.challenge = &challenge{scheme: ch.scheme, parameters: parameters}
+}
+
+func (c *Client) cachedAuthorization(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.tokens[key]
+	if !ok {
+		return "", false
+	}
+
+	if !token.expires.IsZero() && time.Now().After(token.expires) {
+		delete(c.tokens, key)
+		return "", false
+	}
+
+	return token.authorization, true
+}
+
+// cacheAuthorization stores the authorization for the scopes, an expiresIn of zero never expires
+func (c *Client) cacheAuthorization(key string, authorization string, expiresIn time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token := cachedToken{authorization: authorization}
+	if expiresIn > 0 {
+		// renew the token before it expires while a request is in flight
+		if expiresIn > tokenExpiryMargin {
+			expiresIn -= tokenExpiryMargin
+		}
+		token.expires = time.Now().Add(expiresIn)
+	}
+	c.tokens[key] = token
+}
+
+func (c *Client) newRequest(ctx context.Context, r *request) (*http.Request, error) {
+	url := r.path
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = c.baseUrl(ctx) + r.path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, url, r.body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+
+	if r.body != nil && r.size > 0 {
+		req.ContentLength = r.size
+	}
+
+	return req, nil
+}
+
+// baseUrl returns the url of the registry api. Like the docker daemon, insecure registries are
+// reached over https without verifying the certificate and over plain http when https fails.
+func (c *Client) baseUrl(ctx context.Context) string {
+	if !c.insecure {
+		return c.endpoint
+	}
+
+	c.probe.Do(func() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/v2/", nil)
+		if err != nil {
+			return
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			log.Debugf("%s is not served over https, using http: %v", c.host, err)
+			c.endpoint = fmt.Sprintf("http://%s", endpointHost(c.host))
+			return
+		}
+		resp.Body.Close()
+	})
+
+	return c.endpoint
+}
+
+// checkResponse returns an error describing an unexpected response status
+func checkResponse(resp *http.Response, expected ...int) error {
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	message := strings.TrimSpace(string(body))
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return errors.Wrapf(ErrNotFound, "%s %s", resp.Request.Method, resp.Request.URL.Path)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Wrapf(ErrUnauthorized, "%s %s: %s", resp.Request.Method, resp.Request.URL.Path, message)
+	}
+
+	return errors.Errorf("%s %s: unexpected status %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, message)
+}
+
+// cleanHost removes the scheme from a registry address
+func cleanHost(host string) string {
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return host
+}
+
+// endpointHost returns the host serving the registry api
+func endpointHost(host string) string {
+	switch host {
+	case dockerHubHost, "index.docker.io", "registry.hub.docker.com":
+		return dockerHubEndpoint
+	}
+	return host
+}
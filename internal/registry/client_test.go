@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClient_Ping(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+
+	testCases := []struct {
+		name        string
+		auth        string
+		user        *RegistryUser
+		expectedErr error
+	}{
+		{name: "anonymous", auth: ""},
+		{name: "basic", auth: "basic", user: user},
+		{name: "bearer", auth: "bearer", user: user},
+		{name: "basic with invalid credentials", auth: "basic", user: &RegistryUser{Name: "user", Password: "wrong"}, expectedErr: ErrUnauthorized},
+		{name: "bearer with invalid credentials", auth: "bearer", user: &RegistryUser{Name: "user", Password: "wrong"}, expectedErr: ErrUnauthorized},
+		{name: "basic without credentials", auth: "basic", expectedErr: ErrUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeRegistry(t, tc.auth, user)
+
+			err := fake.client(tc.user).Ping(context.Background())
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestClient_tokenCache(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+	fake := newFakeRegistry(t, "bearer", user)
+	fake.PutImage("ns/app", "1.0.0", "amd64")
+	fake.PutImage("ns/other", "1.0.0", "amd64")
+
+	ctx := context.Background()
+	c := fake.client(user)
+
+	// the token is reused for the same scope
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetManifest(ctx, "ns/app", "1.0.0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fake.TokenRequests() != 1 {
+		t.Errorf("expected 1 token request, got %v", fake.TokenRequests())
+	}
+
+	// the first request is challenged, the following are sent with the token
+	if actual := fake.CountRequests("GET /v2/ns/app/manifests/1.0.0"); actual != 3 {
+		t.Errorf("expected 3 requests, got %v", actual)
+	}
+
+	// a token is requested for another repository before sending the request
+	if _, err := c.GetManifest(ctx, "ns/other", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if fake.TokenRequests() != 2 {
+		t.Errorf("expected 2 token requests, got %v", fake.TokenRequests())
+	}
+	if actual := fake.CountRequests("GET /v2/ns/other/manifests/1.0.0"); actual != 1 {
+		t.Errorf("expected 1 request, got %v", actual)
+	}
+
+	// expired tokens are renewed before sending the request
+	c.tokens[repositoryScope("ns/app", "pull")] = cachedToken{
+		authorization: "Bearer token-1",
+		expires:       time.Now().Add(-time.Second),
+	}
+	if _, err := c.GetManifest(ctx, "ns/app", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if fake.TokenRequests() != 3 {
+		t.Errorf("expected 3 token requests, got %v", fake.TokenRequests())
+	}
+	if actual := fake.CountRequests("GET /v2/ns/app/manifests/1.0.0"); actual != 4 {
+		t.Errorf("expected 4 requests, got %v", actual)
+	}
+}
+
+func TestClient_cacheAuthorization(t *testing.T) {
+	c := NewClient("localhost:5000", nil)
+
+	c.cacheAuthorization("basic", "Basic dXNlcjpwYXNzd29yZA==", 0)
+	if _, ok := c.cachedAuthorization("basic"); !ok {
+		t.Error("expected the basic authorization to be cached")
+	}
+	if !c.tokens["basic"].expires.IsZero() {
+		t.Errorf("expected the basic authorization to never expire, got %v", c.tokens["basic"].expires)
+	}
+
+	c.cacheAuthorization("bearer", "Bearer token", 300*time.Second)
+	expires := c.tokens["bearer"].expires
+	if expires.Before(time.Now().Add(280*time.Second)) || expires.After(time.Now().Add(290*time.Second)) {
+		t.Errorf("expected the token to be renewed before it expires, got %v", time.Until(expires))
+	}
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ImageLocation identifies an image in a registry
+type ImageLocation struct {
+	Client     *Client
+	Repository string
+	// Reference is a tag or a digest
+	Reference string
+}
+
+// CopyImage copies an image from the source to the destination, including every image of a manifest
+// list and all of the blobs they reference. The manifests are copied as is so their digests are preserved.
+func CopyImage(ctx context.Context, src ImageLocation, dst ImageLocation) (digest.Digest, error) {
+	manifest, err := src.Client.GetManifest(ctx, src.Repository, src.Reference)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching the source manifest")
+	}
+
+	if manifest.IsIndex() {
+		descriptors, err := manifest.Manifests()
+		if err != nil {
+			return "", err
+		}
+
+		for _, descriptor := range descriptors {
+			if descriptor.Platform != nil {
+				log.Infof("Copying %s/%s image %s", descriptor.Platform.OS, descriptor.Platform.Architecture, descriptor.Digest)
+			}
+
+			child, err := src.Client.GetManifest(ctx, src.Repository, descriptor.Digest.String())
+			if err != nil {
+				return "", errors.Wrapf(err, "fetching the manifest %s", descriptor.Digest)
+			}
+
+			if err := copyManifest(ctx, src, dst, child, child.Digest.String()); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err := copyManifest(ctx, src, dst, manifest, dst.Reference); err != nil {
+		return "", err
+	}
+
+	return manifest.Digest, nil
+}
+
+// copyManifest copies the blobs of an image manifest (if any) and then uploads the manifest
+func copyManifest(ctx context.Context, src ImageLocation, dst ImageLocation, manifest *Manifest, reference string) error {
+	if !manifest.IsIndex() {
+		blobs, err := manifest.Blobs()
+		if err != nil {
+			return err
+		}
+
+		for _, blob := range blobs {
+			if err := copyBlob(ctx, src, dst, blob); err != nil {
+				return errors.Wrapf(err, "copying the blob %s", blob.Digest)
+			}
+		}
+	}
+
+	dgst, err := dst.Client.PutManifest(ctx, dst.Repository, reference, manifest)
+	if err != nil {
+		return errors.Wrapf(err, "uploading the manifest %s", manifest.Digest)
+	}
+
+	if dgst != manifest.Digest {
+		return errors.Errorf("the registry changed the digest of %s to %s", manifest.Digest, dgst)
+	}
+
+	return nil
+}
+
+func copyBlob(ctx context.Context, src ImageLocation, dst ImageLocation, blob Descriptor) error {
+	// foreign layers are not stored in the registry
+	if len(blob.URLs) > 0 {
+		log.Debugf("Skipping foreign layer %s", blob.Digest)
+		return nil
+	}
+
+	exists, err := dst.Client.BlobExists(ctx, dst.Repository, blob.Digest)
+	if err != nil {
+		return err
+	}
+	if exists {
+		log.Debugf("Layer %s already exists", blob.Digest)
+		return nil
+	}
+
+	// blobs can be mounted from another repository in the same registry without transferring them
+	if src.Client.Host() == dst.Client.Host() {
+		mounted, err := dst.Client.MountBlob(ctx, dst.Repository, src.Repository, blob.Digest)
+		if err != nil {
+			return err
+		}
+		if mounted {
+			log.Debugf("Mounted layer %s from %s", blob.Digest, src.Repository)
+			return nil
+		}
+	}
+
+	content, size, err := src.Client.GetBlob(ctx, src.Repository, blob.Digest)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if size <= 0 {
+		size = blob.Size
+	}
+
+	log.Debugf("Uploading layer %s", blob.Digest)
+
+	verifier := blob.Digest.Verifier()
+	if err := dst.Client.UploadBlob(ctx, dst.Repository, blob.Digest, io.TeeReader(content, verifier), size); err != nil {
+		return err
+	}
+
+	if !verifier.Verified() {
+		return errors.Errorf("the content of %s did not match the digest", blob.Digest)
+	}
+
+	return nil
+}
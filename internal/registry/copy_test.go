@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCopyImage(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+	source := newFakeRegistry(t, "bearer", user)
+	target := newFakeRegistry(t, "bearer", user)
+	index := source.PutIndex("ns/app", "1.0.0", "amd64", "arm64")
+
+	ctx := context.Background()
+
+	dgst, err := CopyImage(ctx,
+		ImageLocation{Client: source.client(user), Repository: "ns/app", Reference: "1.0.0"},
+		ImageLocation{Client: target.client(user), Repository: "mirror/app", Reference: "1.0.0"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dgst != index {
+		t.Errorf("expected digest %v, got %v", index, dgst)
+	}
+
+	manifest, err := target.client(user).GetManifest(ctx, "mirror/app", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Digest != index {
+		t.Errorf("expected the copied digest %v, got %v", index, manifest.Digest)
+	}
+
+	descriptors, err := manifest.Manifests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, descriptor := range descriptors {
+		image, err := target.client(user).GetManifest(ctx, "mirror/app", descriptor.Digest.String())
+		if err != nil {
+			t.Fatalf("expected the %v image to be copied: %v", descriptor.Platform.Architecture, err)
+		}
+
+		blobs, _ := image.Blobs()
+		for _, blob := range blobs {
+			if !target.HasBlob("mirror/app", blob.Digest) {
+				t.Errorf("expected the blob %v to be copied", blob.Digest)
+			}
+		}
+	}
+
+	// copying again only checks the blobs exist
+	uploads := target.CountRequests("POST")
+	if _, err := CopyImage(ctx,
+		ImageLocation{Client: source.client(user), Repository: "ns/app", Reference: "1.0.0"},
+		ImageLocation{Client: target.client(user), Repository: "mirror/app", Reference: "1.0.0"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if actual := target.CountRequests("POST"); actual != uploads {
+		t.Errorf("expected no uploads, got %v", actual-uploads)
+	}
+}
+
+func TestCopyImage_mount(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+	fake := newFakeRegistry(t, "bearer", user)
+	image := fake.PutImage("ns/app", "1.0.0", "amd64")
+
+	c := fake.client(user)
+	dgst, err := CopyImage(context.Background(),
+		ImageLocation{Client: c, Repository: "ns/app", Reference: "1.0.0"},
+		ImageLocation{Client: c, Repository: "ns/promoted", Reference: "1.0.0"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dgst != image.Digest {
+		t.Errorf("expected digest %v, got %v", image.Digest, dgst)
+	}
+
+	// the blobs are mounted from the source repository instead of being uploaded
+	if actual := fake.CountRequests("PUT /v2/ns/promoted/blobs/uploads/"); actual != 0 {
+		t.Errorf("expected no uploads, got %v", actual)
+	}
+	if actual := fake.CountRequests("POST /v2/ns/promoted/blobs/uploads/?from=ns%2Fapp&mount="); actual < 2 {
+		t.Errorf("expected the blobs to be mounted, got %v requests", actual)
+	}
+}
+
+func TestCopyImage_errors(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+	source := newFakeRegistry(t, "bearer", user)
+	target := newFakeRegistry(t, "bearer", user)
+	source.PutImage("ns/app", "1.0.0", "amd64")
+
+	testCases := []struct {
+		name   string
+		source ImageLocation
+		target ImageLocation
+	}{
+		{
+			name:   "missing source",
+			source: ImageLocation{Client: source.client(user), Repository: "ns/app", Reference: "2.0.0"},
+			target: ImageLocation{Client: target.client(user), Repository: "mirror/app", Reference: "2.0.0"},
+		},
+		{
+			name:   "source authentication failure",
+			source: ImageLocation{Client: source.client(&RegistryUser{Name: "user", Password: "wrong"}), Repository: "ns/app", Reference: "1.0.0"},
+			target: ImageLocation{Client: target.client(user), Repository: "mirror/app", Reference: "1.0.0"},
+		},
+		{
+			name:   "target authentication failure",
+			source: ImageLocation{Client: source.client(user), Repository: "ns/app", Reference: "1.0.0"},
+			target: ImageLocation{Client: target.client(nil), Repository: "mirror/app", Reference: "1.0.0"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := CopyImage(context.Background(), tc.source, tc.target); err == nil {
+				t.Error("expected an error, but there was none")
+			}
+
+			if _, err := target.client(user).GetManifest(context.Background(), "mirror/app", tc.target.Reference); err == nil {
+				t.Error("expected nothing to be copied")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// credentialExpiryMargin is how long before they expire the credentials of a provider are renewed
+const credentialExpiryMargin = time.Minute
+
+// CredentialProvider runs an executable that prints short-lived registry credentials (i.e. ECR,
+// GCR or ACR tokens), similar to the kubelet credential providers. The credentials are cached and
+// the executable is run again when they are about to expire.
+type CredentialProvider struct {
+	Command string
+	Args    []string
+	// Env are added to the environment of the executable (i.e. AWS_PROFILE=ci)
+	Env []string
+
+	mu      sync.Mutex
+	user    *RegistryUser
+	expires time.Time
+}
+
+// credentialProviderRequest is written to the stdin of the provider
+type credentialProviderRequest struct {
+	ServerAddress string `json:"serverAddress"`
+}
+
+// credentialProviderResponse is read from the stdout of the provider, the credentials do not
+// expire when expiresAt is omitted
+type credentialProviderResponse struct {
+	Username  string     `json:"username"`
+	Password  string     `json:"password"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// Credentials returns the credentials for the registry and when they expire, the expiry is zero
+// when they do not expire
+func (p *CredentialProvider) Credentials(ctx context.Context, serverAddress string) (*RegistryUser, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.user != nil && (p.expires.IsZero() || time.Now().Add(credentialExpiryMargin).Before(p.expires)) {
+		return p.user, p.expires, nil
+	}
+
+	log.Debugf("Requesting the credentials for %s from %s", serverAddress, p.Command)
+
+	response, err := p.run(ctx, serverAddress)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	p.user = &RegistryUser{Name: response.Username, Password: response.Password}
+	p.expires = time.Time{}
+	if response.ExpiresAt != nil {
+		p.expires = *response.ExpiresAt
+	}
+
+	return p.user, p.expires, nil
+}
+
+func (p *CredentialProvider) run(ctx context.Context, serverAddress string) (*credentialProviderResponse, error) {
+	input, err := json.Marshal(credentialProviderRequest{ServerAddress: serverAddress})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Env = append(os.Environ(), p.Env...)
+	cmd.Stdin = bytes.NewReader(input)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("credential provider %s: %v: %s", p.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response credentialProviderResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, errors.Wrapf(err, "parsing the output of the credential provider %s", p.Command)
+	}
+
+	if response.Username == "" || response.Password == "" {
+		return nil, errors.Errorf("credential provider %s did not return a username and password", p.Command)
+	}
+
+	return &response, nil
+}
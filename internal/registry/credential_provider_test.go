@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCredentialProvider writes a provider script that prints the output and counts its runs
+func fakeCredentialProvider(t *testing.T, output string) (*CredentialProvider, func() int) {
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	script := filepath.Join(dir, "provider")
+
+	content := fmt.Sprintf("#!/bin/sh\ncat > %s.input\necho run >> %s\ncat <<'EOF'\n%s\nEOF\n", runs, runs, output)
+	if err := os.WriteFile(script, []byte(content), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	countRuns := func() int {
+		content, err := os.ReadFile(runs)
+		if err != nil {
+			return 0
+		}
+		return strings.Count(string(content), "run")
+	}
+
+	return &CredentialProvider{Command: script}, countRuns
+}
+
+func TestCredentialProvider_Credentials(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expiresAt    string
+		expectedRuns int
+	}{
+		{name: "without expiry", expectedRuns: 1},
+		{name: "valid credentials are cached", expiresAt: time.Now().Add(time.Hour).Format(time.RFC3339), expectedRuns: 1},
+		{name: "expiring credentials are renewed", expiresAt: time.Now().Add(30 * time.Second).Format(time.RFC3339), expectedRuns: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := `{"username": "AWS", "password": "token"}`
+			if tc.expiresAt != "" {
+				output = fmt.Sprintf(`{"username": "AWS", "password": "token", "expiresAt": %q}`, tc.expiresAt)
+			}
+			provider, runs := fakeCredentialProvider(t, output)
+
+			for i := 0; i < 2; i++ {
+				user, _, err := provider.Credentials(context.Background(), "registry.example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if user.Name != "AWS" || user.Password != "token" {
+					t.Errorf("expected the provider credentials, got %+v", user)
+				}
+			}
+
+			if actual := runs(); actual != tc.expectedRuns {
+				t.Errorf("expected %v runs, got %v", tc.expectedRuns, actual)
+			}
+		})
+	}
+}
+
+func TestCredentialProvider_invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+	}{
+		{name: "not json", output: "token"},
+		{name: "missing password", output: `{"username": "AWS"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, _ := fakeCredentialProvider(t, tc.output)
+
+			if _, _, err := provider.Credentials(context.Background(), "registry.example.com"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	provider := &CredentialProvider{Command: filepath.Join(t.TempDir(), "missing")}
+	if _, _, err := provider.Credentials(context.Background(), "registry.example.com"); err == nil {
+		t.Error("expected an error for a missing provider")
+	}
+}
+
+func TestClient_credentialProvider(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+
+	for _, auth := range []string{"basic", "bearer"} {
+		t.Run(auth, func(t *testing.T) {
+			fake := newFakeRegistry(t, auth, user)
+			fake.PutImage("ns/app", "1.0.0", "amd64")
+
+			expiresAt := time.Now().Add(30 * time.Second).Format(time.RFC3339)
+			provider, runs := fakeCredentialProvider(t, fmt.Sprintf(`{"username": "user", "password": "password", "expiresAt": %q}`, expiresAt))
+
+			c := NewClient(fake.Host(), &ClientOptions{
+				CredentialProvider: provider,
+				HttpClient:         fake.Server.Client(),
+			})
+
+			if err := c.Ping(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.GetManifest(context.Background(), "ns/app", "1.0.0"); err != nil {
+				t.Fatal(err)
+			}
+
+			// the credentials expire within the renewal margin, so each authentication renews them
+			if actual := runs(); actual < 2 {
+				t.Errorf("expected the credentials to be renewed, got %v runs", actual)
+			}
+		})
+	}
+
+	fake := newFakeRegistry(t, "basic", user)
+	provider, _ := fakeCredentialProvider(t, `{"username": "user", "password": "wrong"}`)
+	c := NewClient(fake.Host(), &ClientOptions{CredentialProvider: provider, HttpClient: fake.Server.Client()})
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected error %v, got %v", ErrUnauthorized, err)
+	}
+}
@@ -0,0 +1,332 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// dockerHubAuthKey is the key the docker cli stores Docker Hub credentials under
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// identityTokenUsername is returned by credential helpers for identity tokens, which are not supported
+const identityTokenUsername = "<token>"
+
+// dockerConfig is the subset of the docker cli config file used to locate credentials
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore,omitempty"`
+	CredHelpers map[string]string     `json:"credHelpers,omitempty"`
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// helperCredentials is the format used by docker credential helpers
+type helperCredentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// LookupCredentials finds the credentials for the registry stored by 'docker login', using the
+// credential helpers and credential store defined in the docker config when present. Nil is
+// returned when no credentials are stored for the registry.
+func LookupCredentials(serverAddress string) (*RegistryUser, error) {
+	config, err := loadDockerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	key := authKey(serverAddress)
+
+	if helper := config.credentialHelper(key); helper != "" {
+		log.Debugf("Using the docker-credential-%s helper for %s", helper, key)
+		return getHelperCredentials(helper, key)
+	}
+
+	for server, auth := range config.Auths {
+		if authKey(server) != key {
+			continue
+		}
+		return decodeAuth(auth)
+	}
+
+	return nil, nil
+}
+
+// StoreCredentials saves the credentials for the registry the same way 'docker login' does, using
+// the credential helper or store defined in the docker config, or the config file otherwise
+func StoreCredentials(serverAddress string, user *RegistryUser) error {
+	config, err := loadDockerConfig()
+	if err != nil {
+		return err
+	}
+
+	key := authKey(serverAddress)
+
+	if helper := config.credentialHelper(key); helper != "" {
+		credentials, err := json.Marshal(helperCredentials{
+			ServerURL: key,
+			Username:  user.Name,
+			Secret:    user.Password,
+		})
+		if err != nil {
+			return err
+		}
+		return runCredentialHelper(helper, "store", credentials)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(user.Name + ":" + user.Password))
+	return updateDockerConfigAuths(key, &dockerAuth{Auth: auth})
+}
+
+// EraseCredentials removes the credentials stored for the registry the same way 'docker logout' does
+func EraseCredentials(serverAddress string) error {
+	config, err := loadDockerConfig()
+	if err != nil {
+		return err
+	}
+
+	key := authKey(serverAddress)
+
+	if helper := config.credentialHelper(key); helper != "" {
+		if err := runCredentialHelper(helper, "erase", []byte(key)); err != nil {
+			return err
+		}
+	}
+
+	// the docker cli keeps an empty entry when a helper is used, which is removed as well
+	return updateDockerConfigAuths(key, nil)
+}
+
+// SameServer returns true when the addresses refer to the same registry (i.e. docker.io and https://index.docker.io/v1/)
+func SameServer(a string, b string) bool {
+	return authKey(a) == authKey(b)
+}
+
+// DockerConfigPath returns the location of the docker cli config file
+func DockerConfigPath() string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+func loadDockerConfig() (*dockerConfig, error) {
+	config := &dockerConfig{}
+
+	path := DockerConfigPath()
+	if path == "" {
+		return config, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "reading the docker config")
+	}
+
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, errors.Wrapf(err, "parsing the docker config %s", path)
+	}
+
+	return config, nil
+}
+
+// updateDockerConfigAuths replaces the auths entry of the registry in the docker config, removing it
+// when auth is nil. The rest of the config file is preserved as is.
+func updateDockerConfigAuths(key string, auth *dockerAuth) error {
+	path := DockerConfigPath()
+	if path == "" {
+		return errors.New("unable to locate the docker config")
+	}
+
+	config := make(map[string]json.RawMessage)
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "reading the docker config")
+	}
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &config); err != nil {
+			return errors.Wrapf(err, "parsing the docker config %s", path)
+		}
+	}
+
+	auths := make(map[string]json.RawMessage)
+	if raw, ok := config["auths"]; ok {
+		if err := json.Unmarshal(raw, &auths); err != nil {
+			return errors.Wrapf(err, "parsing the auths of the docker config %s", path)
+		}
+	}
+
+	// remove every alias of the registry (i.e. docker.io and https://index.docker.io/v1/)
+	for server := range auths {
+		if authKey(server) == key {
+			delete(auths, server)
+		}
+	}
+
+	if auth != nil {
+		raw, err := json.Marshal(auth)
+		if err != nil {
+			return err
+		}
+		auths[key] = raw
+	}
+
+	raw, err := json.Marshal(auths)
+	if err != nil {
+		return err
+	}
+	config["auths"] = raw
+
+	content, err = json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(err, "creating the docker config directory")
+	}
+
+	// write to a temporary file first so the config is never left partially written
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return errors.Wrap(err, "writing the docker config")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "writing the docker config")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "writing the docker config")
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return errors.Wrap(err, "writing the docker config")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), path), "writing the docker config")
+}
+
+// credentialHelper returns the name of the helper that stores the credentials for the registry
+func (c *dockerConfig) credentialHelper(key string) string {
+	for server, helper := range c.CredHelpers {
+		if authKey(server) == key {
+			return helper
+		}
+	}
+	return c.CredsStore
+}
+
+// getHelperCredentials runs 'docker-credential-<helper> get' to retrieve the credentials
+func getHelperCredentials(helper string, serverAddress string) (*RegistryUser, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverAddress)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(string(output) + stderr.String())
+		if strings.Contains(strings.ToLower(message), "credentials not found") {
+			return nil, nil
+		}
+		return nil, errors.Errorf("docker-credential-%s get: %v: %s", helper, err, message)
+	}
+
+	var credentials helperCredentials
+	if err := json.Unmarshal(output, &credentials); err != nil {
+		return nil, errors.Wrapf(err, "parsing the docker-credential-%s output", helper)
+	}
+
+	if credentials.Username == identityTokenUsername {
+		log.Debugf("Identity tokens are not supported, ignoring the stored credentials for %s", serverAddress)
+		return nil, nil
+	}
+
+	if credentials.Username == "" || credentials.Secret == "" {
+		return nil, nil
+	}
+
+	return &RegistryUser{
+		Name:     credentials.Username,
+		Password: credentials.Secret,
+	}, nil
+}
+
+// runCredentialHelper runs 'docker-credential-<helper> <action>' with the input on stdin
+func runCredentialHelper(helper string, action string, input []byte) error {
+	cmd := exec.Command("docker-credential-"+helper, action)
+	cmd.Stdin = bytes.NewReader(input)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if action == "erase" && strings.Contains(strings.ToLower(message), "credentials not found") {
+			return nil
+		}
+		return errors.Errorf("docker-credential-%s %s: %v: %s", helper, action, err, message)
+	}
+
+	return nil
+}
+
+// decodeAuth returns the credentials from an auths entry of the docker config
+func decodeAuth(auth dockerAuth) (*RegistryUser, error) {
+	if auth.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding the docker config auth")
+		}
+
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, errors.New("invalid auth in the docker config")
+		}
+		return &RegistryUser{Name: username, Password: password}, nil
+	}
+
+	if auth.Username != "" && auth.Password != "" {
+		return &RegistryUser{Name: auth.Username, Password: auth.Password}, nil
+	}
+
+	if auth.IdentityToken != "" {
+		log.Debug("Identity tokens are not supported, ignoring the stored credentials")
+	}
+
+	return nil, nil
+}
+
+// authKey normalizes a registry address to the key used by the docker cli
+func authKey(serverAddress string) string {
+	host := cleanHost(serverAddress)
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case dockerHubHost, "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return dockerHubAuthKey
+	}
+
+	return host
+}
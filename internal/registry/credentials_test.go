@@ -0,0 +1,190 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDockerConfig creates a docker config in a temporary directory and points DOCKER_CONFIG at it
+func writeDockerConfig(t *testing.T, content string) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+// writeCredentialHelper creates a fake docker-credential-<name> helper and adds it to the PATH
+func writeCredentialHelper(t *testing.T, name string, script string) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-credential-"+name), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestLookupCredentials_auths(t *testing.T) {
+	// "user:password" base64 encoded
+	writeDockerConfig(t, `{"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNzd29yZA=="},
+		"registry.example.com": {"username": "other", "password": "secret"}
+	}}`)
+
+	testCases := []struct {
+		name         string
+		registry     string
+		expectedUser string
+		expectedPass string
+	}{
+		{name: "docker hub", registry: "docker.io", expectedUser: "user", expectedPass: "password"},
+		{name: "private registry", registry: "https://registry.example.com", expectedUser: "other", expectedPass: "secret"},
+		{name: "unknown registry", registry: "ghcr.io"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user, err := LookupCredentials(tc.registry)
+			if err != nil {
+				t.Fatalf("An unexpected error occurred: %v", err)
+			}
+
+			if tc.expectedUser == "" {
+				if user != nil {
+					t.Errorf("expected no credentials, got %v", user.Name)
+				}
+				return
+			}
+
+			if user == nil || user.Name != tc.expectedUser || user.Password != tc.expectedPass {
+				t.Errorf("expected %v:%v, got %+v", tc.expectedUser, tc.expectedPass, user)
+			}
+		})
+	}
+}
+
+func TestLookupCredentials_helpers(t *testing.T) {
+	writeDockerConfig(t, `{
+		"credsStore": "store",
+		"credHelpers": {"ghcr.io": "ghcr"}
+	}`)
+	writeCredentialHelper(t, "store", `#!/bin/sh
+read server
+echo "{\"ServerURL\": \"$server\", \"Username\": \"store-user\", \"Secret\": \"store-secret\"}"
+`)
+	writeCredentialHelper(t, "ghcr", `#!/bin/sh
+read server
+if [ "$server" != "ghcr.io" ]; then
+	echo "credentials not found in native keychain"
+	exit 1
+fi
+echo '{"ServerURL": "ghcr.io", "Username": "ghcr-user", "Secret": "ghcr-secret"}'
+`)
+
+	user, err := LookupCredentials("ghcr.io")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if user == nil || user.Name != "ghcr-user" || user.Password != "ghcr-secret" {
+		t.Errorf("expected the credential helper to be used, got %+v", user)
+	}
+
+	user, err = LookupCredentials("docker.io")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if user == nil || user.Name != "store-user" || user.Password != "store-secret" {
+		t.Errorf("expected the credential store to be used, got %+v", user)
+	}
+}
+
+func TestNewRegistry_dockerCredentials(t *testing.T) {
+	writeDockerConfig(t, `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNzd29yZA=="}}}`)
+
+	registry, err := NewRegistry("registry.example.com", "namespace", "", "")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if registry.User.Name != "user" || registry.User.Password != "password" {
+		t.Errorf("expected the docker credentials to be used, got %+v", registry.User)
+	}
+
+	if _, err := NewRegistry("ghcr.io", "namespace", "", ""); err == nil {
+		t.Error("expected an error when there are no credentials")
+	}
+}
+
+func TestStoreCredentials_config(t *testing.T) {
+	writeDockerConfig(t, `{"auths": {"docker.io": {"auth": "b2xkOm9sZA=="}}, "currentContext": "remote"}`)
+
+	if err := StoreCredentials("docker.io", &RegistryUser{Name: "user", Password: "password"}); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+
+	user, err := LookupCredentials("docker.io")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if user == nil || user.Name != "user" || user.Password != "password" {
+		t.Errorf("expected the stored credentials, got %+v", user)
+	}
+
+	content, err := os.ReadFile(DockerConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"currentContext": "remote"`) {
+		t.Errorf("expected the rest of the config to be preserved, got %s", content)
+	}
+	if strings.Contains(string(content), `"docker.io"`) {
+		t.Errorf("expected the previous entry to be replaced, got %s", content)
+	}
+
+	if err := EraseCredentials("docker.io"); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+
+	user, err = LookupCredentials("docker.io")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if user != nil {
+		t.Errorf("expected the credentials to be erased, got %+v", user)
+	}
+}
+
+func TestStoreCredentials_helper(t *testing.T) {
+	writeDockerConfig(t, `{"credsStore": "store"}`)
+	stored := filepath.Join(t.TempDir(), "stored")
+	writeCredentialHelper(t, "store", `#!/bin/sh
+case "$1" in
+store) cat > `+stored+` ;;
+erase) read server; echo "erased $server" > `+stored+` ;;
+esac
+`)
+
+	if err := StoreCredentials("ghcr.io", &RegistryUser{Name: "user", Password: "password"}); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+
+	content, err := os.ReadFile(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"ServerURL":"ghcr.io","Username":"user","Secret":"password"}`
+	if expected != string(content) {
+		t.Errorf("expected %v, got %s", expected, content)
+	}
+
+	if err := EraseCredentials("ghcr.io"); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+
+	content, err = os.ReadFile(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "erased ghcr.io\n"; expected != string(content) {
+		t.Errorf("expected %v, got %s", expected, content)
+	}
+}
@@ -0,0 +1,27 @@
+package registry
+
+import (
+	"testing"
+	"tugboat/internal/registry/registrytest"
+)
+
+// fakeRegistry is the in-memory registry of registrytest with a client trusting its certificate
+type fakeRegistry struct {
+	*registrytest.Registry
+}
+
+func newFakeRegistry(t *testing.T, auth string, user *RegistryUser) *fakeRegistry {
+	var fakeUser *registrytest.User
+	if user != nil {
+		fakeUser = &registrytest.User{Name: user.Name, Password: user.Password}
+	}
+	return &fakeRegistry{registrytest.New(t, auth, fakeUser)}
+}
+
+// client creates a client for the registry that trusts its certificate
+func (f *fakeRegistry) client(user *RegistryUser) *Client {
+	return NewClient(f.Host(), &ClientOptions{
+		User:       user,
+		HttpClient: f.Server.Client(),
+	})
+}
@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// The manifest media types supported by the client
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOciManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOciIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// manifestMediaTypes is sent in the Accept header so the registry does not convert the manifest
+var manifestMediaTypes = []string{
+	MediaTypeOciIndex,
+	MediaTypeDockerManifestList,
+	MediaTypeOciManifest,
+	MediaTypeDockerManifest,
+}
+
+// Manifest is a raw manifest as stored in the registry. The content is kept as is
+// so the digest is preserved when the manifest is copied.
+type Manifest struct {
+	MediaType string
+	Digest    digest.Digest
+	Content   []byte
+}
+
+// Descriptor describes the content referenced by a manifest
+type Descriptor struct {
+	MediaType string        `json:"mediaType"`
+	Digest    digest.Digest `json:"digest"`
+	Size      int64         `json:"size"`
+	URLs      []string      `json:"urls,omitempty"`
+	Platform  *Platform     `json:"platform,omitempty"`
+}
+
+// Platform describes the platform an image in a manifest list runs on
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// manifestContent contains the fields of both image manifests and manifest lists
+type manifestContent struct {
+	MediaType string       `json:"mediaType"`
+	Config    *Descriptor  `json:"config,omitempty"`
+	Layers    []Descriptor `json:"layers,omitempty"`
+	Manifests []Descriptor `json:"manifests,omitempty"`
+}
+
+// IsIndex returns true if the manifest is a manifest list or an image index
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == MediaTypeDockerManifestList || m.MediaType == MediaTypeOciIndex
+}
+
+// Manifests returns the descriptors of the images in a manifest list
+func (m *Manifest) Manifests() ([]Descriptor, error) {
+	content, err := m.parse()
+	if err != nil {
+		return nil, err
+	}
+	return content.Manifests, nil
+}
+
+// Blobs returns the descriptors of the config and layers of an image manifest
+func (m *Manifest) Blobs() ([]Descriptor, error) {
+	content, err := m.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	var blobs []Descriptor
+	if content.Config != nil {
+		blobs = append(blobs, *content.Config)
+	}
+	return append(blobs, content.Layers...), nil
+}
+
+func (m *Manifest) parse() (*manifestContent, error) {
+	var content manifestContent
+	if err := json.Unmarshal(m.Content, &content); err != nil {
+		return nil, errors.Wrap(err, "parsing the manifest")
+	}
+	return &content, nil
+}
+
+// GetManifest fetches the manifest for the reference (tag or digest) in the repository
+func (c *Client) GetManifest(ctx context.Context, repository string, reference string) (*Manifest, error) {
+	resp, err := c.do(ctx, &request{
+		method:  http.MethodGet,
+		path:    manifestPath(repository, reference),
+		headers: map[string]string{"Accept": strings.Join(manifestMediaTypes, ", ")},
+		scopes:  []string{repositoryScope(repository, "pull")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if mediaType == "" || mediaType == "application/json" {
+		// fall back to the media type defined in the manifest
+		var parsed manifestContent
+		if err := json.Unmarshal(content, &parsed); err == nil && parsed.MediaType != "" {
+			mediaType = parsed.MediaType
+		}
+	}
+
+	manifestDigest := digest.FromBytes(content)
+	if dgst, err := digest.Parse(reference); err == nil && dgst != manifestDigest {
+		return nil, errors.Errorf("manifest digest mismatch for %s@%s: got %s", repository, reference, manifestDigest)
+	}
+
+	return &Manifest{
+		MediaType: mediaType,
+		Digest:    manifestDigest,
+		Content:   content,
+	}, nil
+}
+
+// PutManifest uploads the manifest to the repository under the reference (tag or digest)
+func (c *Client) PutManifest(ctx context.Context, repository string, reference string, manifest *Manifest) (digest.Digest, error) {
+	resp, err := c.do(ctx, &request{
+		method:  http.MethodPut,
+		path:    manifestPath(repository, reference),
+		headers: map[string]string{"Content-Type": manifest.MediaType},
+		body:    bytes.NewReader(manifest.Content),
+		size:    int64(len(manifest.Content)),
+		scopes:  []string{repositoryScope(repository, "pull", "push")},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, http.StatusCreated, http.StatusOK); err != nil {
+		return "", err
+	}
+
+	if header := resp.Header.Get("Docker-Content-Digest"); header != "" {
+		return digest.Digest(header), nil
+	}
+
+	return digest.FromBytes(manifest.Content), nil
+}
+
+// HeadManifest returns the descriptor of the manifest for the reference without downloading it
+func (c *Client) HeadManifest(ctx context.Context, repository string, reference string) (*Descriptor, error) {
+	resp, err := c.do(ctx, &request{
+		method:  http.MethodHead,
+		path:    manifestPath(repository, reference),
+		headers: map[string]string{"Accept": strings.Join(manifestMediaTypes, ", ")},
+		scopes:  []string{repositoryScope(repository, "pull")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Get("Docker-Content-Digest")
+	if header == "" {
+		return nil, errors.Errorf("%s did not return the digest of %s:%s", c.host, repository, reference)
+	}
+
+	dgst, err := digest.Parse(header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the digest of %s:%s", repository, reference)
+	}
+
+	return &Descriptor{
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    dgst,
+		Size:      resp.ContentLength,
+	}, nil
+}
+
+// DeleteManifest removes the manifest from the repository along with every tag referring to it.
+// Registries only support deleting manifests by digest.
+func (c *Client) DeleteManifest(ctx context.Context, repository string, dgst digest.Digest) error {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodDelete,
+		path:   manifestPath(repository, dgst.String()),
+		scopes: []string{repositoryScope(repository, "delete")},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, http.StatusAccepted, http.StatusOK)
+}
+
+func manifestPath(repository string, reference string) string {
+	return fmt.Sprintf("/v2/%s/manifests/%s", repository, reference)
+}
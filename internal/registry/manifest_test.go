@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClient_manifests(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+	fake := newFakeRegistry(t, "bearer", user)
+	index := fake.PutIndex("ns/app", "1.0.0", "amd64", "arm64")
+
+	ctx := context.Background()
+	c := fake.client(user)
+
+	manifest, err := c.GetManifest(ctx, "ns/app", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Digest != index || !manifest.IsIndex() {
+		t.Errorf("expected the manifest list %v, got %v (%v)", index, manifest.Digest, manifest.MediaType)
+	}
+
+	descriptor, err := c.HeadManifest(ctx, "ns/app", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if descriptor.Digest != index || descriptor.MediaType != MediaTypeDockerManifestList || descriptor.Size != int64(len(manifest.Content)) {
+		t.Errorf("expected the descriptor of %v, got %+v", index, descriptor)
+	}
+
+	dgst, err := c.PutManifest(ctx, "ns/app", "latest", manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dgst != index {
+		t.Errorf("expected digest %v, got %v", index, dgst)
+	}
+
+	if err := c.DeleteManifest(ctx, "ns/app", index); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range []string{"1.0.0", "latest"} {
+		if _, err := c.HeadManifest(ctx, "ns/app", tag); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %v to be deleted, got %v", tag, err)
+		}
+	}
+
+	if _, err := c.GetManifest(ctx, "ns/missing", "1.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestClient_GetManifest_digest(t *testing.T) {
+	fake := newFakeRegistry(t, "", nil)
+	image := fake.PutImage("ns/app", "1.0.0", "amd64")
+
+	manifest, err := fake.client(nil).GetManifest(context.Background(), "ns/app", image.Digest.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.MediaType != MediaTypeDockerManifest || manifest.IsIndex() {
+		t.Errorf("expected an image manifest, got %v", manifest.MediaType)
+	}
+
+	blobs, err := manifest.Blobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 2 {
+		t.Errorf("expected the config and a layer, got %v", blobs)
+	}
+}
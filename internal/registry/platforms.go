@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Platforms returns the platforms an image runs on, which are the platforms of the images of a
+// manifest list or the platform in the config of a single image. Attestations and other entries
+// of unknown platform are left out.
+func (c *Client) Platforms(ctx context.Context, repository string, reference string) ([]Platform, error) {
+	manifest, err := c.GetManifest(ctx, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.IsIndex() {
+		descriptors, err := manifest.Manifests()
+		if err != nil {
+			return nil, err
+		}
+
+		var platforms []Platform
+		for _, descriptor := range descriptors {
+			if descriptor.Platform != nil && descriptor.Platform.Architecture != "unknown" {
+				platforms = append(platforms, *descriptor.Platform)
+			}
+		}
+		return platforms, nil
+	}
+
+	blobs, err := manifest.Blobs()
+	if err != nil {
+		return nil, err
+	}
+	if len(blobs) == 0 {
+		return nil, errors.Errorf("%s:%s has no config", repository, reference)
+	}
+
+	content, _, err := c.GetBlob(ctx, repository, blobs[0].Digest)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var platform Platform
+	if err := json.NewDecoder(io.LimitReader(content, 1<<20)).Decode(&platform); err != nil {
+		return nil, errors.Wrapf(err, "parsing the config of %s:%s", repository, reference)
+	}
+
+	return []Platform{platform}, nil
+}
@@ -0,0 +1,44 @@
+package registry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Platforms(t *testing.T) {
+	fake := newFakeRegistry(t, "", nil)
+	fake.PutIndex("library/alpine", "3", "amd64", "arm64")
+	fake.PutImage("library/app", "1.0.0", "arm64")
+
+	c := fake.client(nil)
+
+	testCases := []struct {
+		repository string
+		tag        string
+		expected   []string
+	}{
+		{repository: "library/alpine", tag: "3", expected: []string{"amd64", "arm64"}},
+		{repository: "library/app", tag: "1.0.0", expected: []string{"arm64"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.repository, func(t *testing.T) {
+			platforms, err := c.Platforms(context.Background(), tc.repository, tc.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, platform := range platforms {
+				actual = append(actual, platform.Architecture)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if _, err := c.Platforms(context.Background(), "library/missing", "latest"); err == nil {
+		t.Error("expected an error for a missing image")
+	}
+}
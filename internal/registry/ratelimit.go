@@ -0,0 +1,173 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// rateLimitWarning is the share of the rate limit left when a warning is shown
+	rateLimitWarning = 0.1
+	// defaultRateLimitDelay is waited when a rate limited response does not say when to retry
+	defaultRateLimitDelay = time.Minute
+	// minRateLimitDelay is the shortest wait between rate limited requests
+	minRateLimitDelay = time.Second
+)
+
+var ErrRateLimited = errors.New("registry rate limit exceeded")
+
+// RateLimit is the pull rate limit reported by the registry (i.e. Docker Hub)
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	// Window is the period the limit applies to
+	Window time.Duration
+}
+
+// RateLimit returns the last rate limit reported by the registry, nil when the registry did not report one
+func (c *Client) RateLimit() *RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rateLimit
+}
+
+// observeRateLimit stores the rate limit of the response and warns once when few requests remain
+func (c *Client) observeRateLimit(resp *http.Response) {
+	limit := parseRateLimit(resp.Header)
+	if limit == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rateLimit = limit
+	if c.rateLimitWarned || float64(limit.Remaining) > float64(limit.Limit)*rateLimitWarning {
+		return
+	}
+	c.rateLimitWarned = true
+
+	log.Warnf("%d of %d pulls remain in the %s rate limit window of %s", limit.Remaining, limit.Limit, limit.Window, c.host)
+}
+
+// send sends the request, waiting for the rate limit to reset while the responses are rate
+// limited and the total wait is below the configured maximum
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	var waited time.Duration
+	for {
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		c.observeRateLimit(resp)
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		delay := retryDelay(resp.Header, c.RateLimit())
+		if waited >= c.rateLimitWait {
+			return nil, errors.Wrapf(ErrRateLimited, "%s %s: retry in %s", req.Method, req.URL.Path, delay)
+		}
+
+		// retry once more at the end of the wait when the limit resets later
+		if delay > c.rateLimitWait-waited {
+			delay = c.rateLimitWait - waited
+		}
+
+		if req.Body != nil && req.GetBody == nil {
+			return nil, errors.Wrapf(ErrRateLimited, "%s %s: unable to resend the request body", req.Method, req.URL.Path)
+		}
+
+		log.Warnf("The rate limit of %s was reached, retrying in %s", c.host, delay)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		waited += delay
+
+		retry := req.Clone(ctx)
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = retry
+	}
+}
+
+// parseRateLimit reads the ratelimit-limit and ratelimit-remaining headers (i.e. 100;w=21600)
+func parseRateLimit(header http.Header) *RateLimit {
+	limit, window, ok := parseRateLimitHeader(header.Get("ratelimit-limit"))
+	if !ok {
+		return nil
+	}
+
+	remaining, _, ok := parseRateLimitHeader(header.Get("ratelimit-remaining"))
+	if !ok {
+		return nil
+	}
+
+	return &RateLimit{Limit: limit, Remaining: remaining, Window: window}
+}
+
+func parseRateLimitHeader(value string) (int, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(value, ";")
+	count, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var window time.Duration
+	for _, part := range parts[1:] {
+		if seconds, ok := strings.CutPrefix(strings.TrimSpace(part), "w="); ok {
+			if n, err := strconv.Atoi(seconds); err == nil {
+				window = time.Duration(n) * time.Second
+			}
+		}
+	}
+
+	return count, window, true
+}
+
+// retryDelay returns how long to wait before retrying a rate limited request, using the
+// Retry-After header when present and otherwise the rate limit window
+func retryDelay(header http.Header, limit *RateLimit) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return max(time.Duration(seconds)*time.Second, minRateLimitDelay)
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return max(time.Until(date), minRateLimitDelay)
+		}
+	}
+
+	if limit != nil && limit.Window > 0 {
+		return limit.Window
+	}
+
+	return defaultRateLimitDelay
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rateLimitedRegistry replies with a 429 to the first limited requests and reports the rate limit headers
+func rateLimitedRegistry(t *testing.T, limited int) (*httptest.Server, *int) {
+	var mu sync.Mutex
+	requests := 0
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		w.Header().Set("ratelimit-limit", "100;w=21600")
+		if requests <= limited {
+			w.Header().Set("ratelimit-remaining", "0;w=21600")
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("ratelimit-remaining", "5;w=21600")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClient_rateLimit(t *testing.T) {
+	testCases := []struct {
+		name             string
+		limited          int
+		wait             time.Duration
+		expectedErr      error
+		expectedRequests int
+	}{
+		{name: "not limited", limited: 0, expectedRequests: 1},
+		{name: "fails without waiting", limited: 1, expectedErr: ErrRateLimited, expectedRequests: 1},
+		{name: "waits for the limit to reset", limited: 1, wait: 5 * time.Second, expectedRequests: 2},
+		{name: "stops waiting after the maximum", limited: 5, wait: 1500 * time.Millisecond, expectedErr: ErrRateLimited, expectedRequests: 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, requests := rateLimitedRegistry(t, tc.limited)
+			c := NewClient(server.Listener.Addr().String(), &ClientOptions{
+				HttpClient:    server.Client(),
+				RateLimitWait: tc.wait,
+			})
+
+			err := c.Ping(context.Background())
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+
+			if *requests != tc.expectedRequests {
+				t.Errorf("expected %v requests, got %v", tc.expectedRequests, *requests)
+			}
+
+			limit := c.RateLimit()
+			if limit == nil || limit.Limit != 100 || limit.Window != 6*time.Hour {
+				t.Errorf("expected the rate limit to be recorded, got %+v", limit)
+			}
+		})
+	}
+}
+
+func Test_parseRateLimit(t *testing.T) {
+	testCases := []struct {
+		name      string
+		limit     string
+		remaining string
+		expected  *RateLimit
+	}{
+		{name: "docker hub", limit: "100;w=21600", remaining: "76;w=21600", expected: &RateLimit{Limit: 100, Remaining: 76, Window: 6 * time.Hour}},
+		{name: "without a window", limit: "200", remaining: "10", expected: &RateLimit{Limit: 200, Remaining: 10}},
+		{name: "missing remaining", limit: "100;w=21600"},
+		{name: "not reported"},
+		{name: "invalid", limit: "many", remaining: "some"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			if tc.limit != "" {
+				header.Set("ratelimit-limit", tc.limit)
+			}
+			if tc.remaining != "" {
+				header.Set("ratelimit-remaining", tc.remaining)
+			}
+
+			actual := parseRateLimit(header)
+			if tc.expected == nil {
+				if actual != nil {
+					t.Errorf("expected no rate limit, got %+v", actual)
+				}
+				return
+			}
+
+			if actual == nil || *actual != *tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_retryDelay(t *testing.T) {
+	testCases := []struct {
+		name       string
+		retryAfter string
+		limit      *RateLimit
+		expected   time.Duration
+	}{
+		{name: "retry after seconds", retryAfter: "30", expected: 30 * time.Second},
+		{name: "retry after now", retryAfter: "0", expected: minRateLimitDelay},
+		{name: "rate limit window", limit: &RateLimit{Window: time.Hour}, expected: time.Hour},
+		{name: "default", expected: defaultRateLimitDelay},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			if tc.retryAfter != "" {
+				header.Set("Retry-After", tc.retryAfter)
+			}
+
+			if actual := retryDelay(header, tc.limit); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -1,11 +1,25 @@
 package registry
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+	"tugboat/internal/pkg/reference"
+
+	log "github.com/sirupsen/logrus"
+)
 
 type Registry struct {
 	ServerAddress string
 	Namespace     string
 	User          *RegistryUser
+	// DockerCredentials is true when the credentials were stored by 'docker login'
+	DockerCredentials bool
+	TLS               TLSOptions
+	// RateLimitWait is the longest time waited for the rate limit of the registry to reset
+	RateLimitWait time.Duration
+	// CredentialProvider renews the credentials of the registry when they expire
+	CredentialProvider *CredentialProvider
 }
 
 type RegistryUser struct {
@@ -13,7 +27,27 @@ type RegistryUser struct {
 	Password string
 }
 
+// NewRegistry creates a registry with the given credentials. When no credentials are provided the
+// credentials stored by 'docker login' are used instead. A server address starting with http://
+// marks the registry as insecure.
 func NewRegistry(serverAddress, namespace, username, password string) (*Registry, error) {
+	insecure := reference.IsInsecure(serverAddress)
+	serverAddress = cleanHost(serverAddress)
+
+	dockerCredentials := false
+	if serverAddress != "" && username == "" && password == "" {
+		user, err := LookupCredentials(serverAddress)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			log.Debugf("Using the docker credentials stored for %s", serverAddress)
+			username = user.Name
+			password = user.Password
+			dockerCredentials = true
+		}
+	}
+
 	if serverAddress == "" || username == "" || password == "" {
 		return nil, errors.New("invalid registry parameters: registry url, username, and password must be provided")
 	}
@@ -25,5 +59,30 @@ func NewRegistry(serverAddress, namespace, username, password string) (*Registry
 			Name:     username,
 			Password: password,
 		},
+		DockerCredentials: dockerCredentials,
+		TLS: TLSOptions{
+			Insecure: insecure,
+		},
 	}, nil
 }
+
+// Client returns a client to communicate with the registry api using the registry credentials
+func (r *Registry) Client() *Client {
+	return NewClient(r.ServerAddress, &ClientOptions{
+		User:               r.User,
+		CredentialProvider: r.CredentialProvider,
+		TLS:                r.TLS,
+		RateLimitWait:      r.RateLimitWait,
+	})
+}
+
+// Credentials returns the current credentials of the registry, renewing them with the credential
+// provider when they expire
+func (r *Registry) Credentials(ctx context.Context) (*RegistryUser, error) {
+	if r.CredentialProvider == nil {
+		return r.User, nil
+	}
+
+	user, _, err := r.CredentialProvider.Credentials(ctx, r.ServerAddress)
+	return user, err
+}
@@ -0,0 +1,442 @@
+// Package registrytest provides an in-memory registry for the tests of the packages using the registry client
+package registrytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Registry is an in-memory stand in for a registry implementing the parts of the distribution
+// api used by the client, with optional basic or bearer token authentication
+type Registry struct {
+	Server *httptest.Server
+	// auth is the authentication scheme required by the registry: "", "basic" or "bearer"
+	auth string
+	// user is the only accepted credential, anonymous access is allowed when nil
+	user *User
+	// PageSize limits the number of tags returned per page when non-zero
+	PageSize int
+	// ExpiresIn is returned with the bearer tokens when non-zero
+	ExpiresIn int
+	// Header is added to the responses of the registry (i.e. the rate limit headers)
+	Header http.Header
+
+	mu            sync.Mutex
+	manifests     map[string]map[digest.Digest]fakeManifest
+	tags          map[string]map[string]digest.Digest
+	blobs         map[string]map[digest.Digest][]byte
+	uploads       map[string]string
+	tokens        map[string][]string
+	tokenRequests int
+	requests      []string
+}
+
+// User is the credential accepted by the registry
+type User struct {
+	Name     string
+	Password string
+}
+
+// Descriptor describes a manifest or a blob stored in the registry
+type Descriptor struct {
+	MediaType string        `json:"mediaType"`
+	Digest    digest.Digest `json:"digest"`
+	Size      int64         `json:"size"`
+	Platform  *Platform     `json:"platform,omitempty"`
+}
+
+// Platform is the platform of an image in a manifest list
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// The media types of the manifests stored by PutImage and PutIndex
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+type fakeManifest struct {
+	mediaType string
+	content   []byte
+}
+
+// New starts a registry requiring the authentication scheme ("", "basic" or "bearer") and
+// accepting the user, which is stopped at the end of the test
+func New(t *testing.T, auth string, user *User) *Registry {
+	f := &Registry{
+		auth:      auth,
+		user:      user,
+		manifests: make(map[string]map[digest.Digest]fakeManifest),
+		tags:      make(map[string]map[string]digest.Digest),
+		blobs:     make(map[string]map[digest.Digest][]byte),
+		uploads:   make(map[string]string),
+		tokens:    make(map[string][]string),
+	}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Server.Close)
+	return f
+}
+
+// Host returns the address of the registry (i.e. 127.0.0.1:1234)
+func (f *Registry) Host() string {
+	return f.Server.Listener.Addr().String()
+}
+
+// TokenRequests returns the number of bearer tokens requested
+func (f *Registry) TokenRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.tokenRequests
+}
+
+// PutBlob stores the content in the repository and returns its descriptor
+func (f *Registry) PutBlob(repository string, mediaType string, content []byte) Descriptor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dgst := digest.FromBytes(content)
+	if f.blobs[repository] == nil {
+		f.blobs[repository] = make(map[digest.Digest][]byte)
+	}
+	f.blobs[repository][dgst] = content
+
+	return Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(content))}
+}
+
+// PutManifest stores the manifest in the repository, tagging it when a tag is given
+func (f *Registry) PutManifest(repository string, tag string, mediaType string, content []byte) digest.Digest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dgst := digest.FromBytes(content)
+	if f.manifests[repository] == nil {
+		f.manifests[repository] = make(map[digest.Digest]fakeManifest)
+		f.tags[repository] = make(map[string]digest.Digest)
+	}
+	f.manifests[repository][dgst] = fakeManifest{mediaType: mediaType, content: content}
+	if tag != "" {
+		f.tags[repository][tag] = dgst
+	}
+
+	return dgst
+}
+
+// PutImage stores an image made of a config and a single layer, returning the descriptor of its manifest
+func (f *Registry) PutImage(repository string, tag string, arch string) Descriptor {
+	config := f.PutBlob(repository, "application/vnd.docker.container.image.v1+json", []byte(fmt.Sprintf(`{"architecture":%q,"os":"linux"}`, arch)))
+	layer := f.PutBlob(repository, "application/vnd.docker.image.rootfs.diff.tar.gzip", []byte("layer-"+arch))
+
+	content, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     MediaTypeDockerManifest,
+		"config":        config,
+		"layers":        []Descriptor{layer},
+	})
+	dgst := f.PutManifest(repository, tag, MediaTypeDockerManifest, content)
+
+	return Descriptor{
+		MediaType: MediaTypeDockerManifest,
+		Digest:    dgst,
+		Size:      int64(len(content)),
+		Platform:  &Platform{Architecture: arch, OS: "linux"},
+	}
+}
+
+// PutIndex stores a manifest list with an image for each architecture
+func (f *Registry) PutIndex(repository string, tag string, archs ...string) digest.Digest {
+	var manifests []Descriptor
+	for _, arch := range archs {
+		manifests = append(manifests, f.PutImage(repository, "", arch))
+	}
+
+	content, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     MediaTypeDockerManifestList,
+		"manifests":     manifests,
+	})
+	return f.PutManifest(repository, tag, MediaTypeDockerManifestList, content)
+}
+
+// HasBlob returns true if the blob is stored in the repository
+func (f *Registry) HasBlob(repository string, dgst digest.Digest) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.blobs[repository][dgst]
+	return ok
+}
+
+// CountRequests returns the number of requests received starting with the prefix (i.e. "POST /v2/")
+func (f *Registry) CountRequests(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *Registry) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		f.serveToken(w, r)
+		return
+	}
+
+	f.requests = append(f.requests, fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI()))
+	for key, values := range f.Header {
+		w.Header()[key] = values
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	if path == "" {
+		if f.authorized(w, r, "", "") {
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+
+	action := "pull"
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		action = "push"
+	case http.MethodDelete:
+		action = "delete"
+	}
+
+	switch {
+	case strings.Contains(path, "/manifests/"):
+		repository, reference, _ := strings.Cut(path, "/manifests/")
+		if f.authorized(w, r, repository, action) {
+			f.serveManifest(w, r, repository, reference)
+		}
+	case strings.Contains(path, "/blobs/uploads/"):
+		repository, id, _ := strings.Cut(path, "/blobs/uploads/")
+		if f.authorized(w, r, repository, "push") {
+			f.serveUpload(w, r, repository, id)
+		}
+	case strings.Contains(path, "/blobs/"):
+		repository, dgst, _ := strings.Cut(path, "/blobs/")
+		if f.authorized(w, r, repository, action) {
+			f.serveBlob(w, r, repository, digest.Digest(dgst))
+		}
+	case strings.HasSuffix(path, "/tags/list"):
+		repository := strings.TrimSuffix(path, "/tags/list")
+		if f.authorized(w, r, repository, "pull") {
+			f.serveTags(w, r, repository)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorized checks the request is allowed the action on the repository, replying with a challenge when it is not
+func (f *Registry) authorized(w http.ResponseWriter, r *http.Request, repository string, action string) bool {
+	switch f.auth {
+	case "basic":
+		username, password, ok := r.BasicAuth()
+		if f.user == nil || (ok && username == f.user.Name && password == f.user.Password) {
+			return true
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+	case "bearer":
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if scopes, ok := f.tokens[token]; ok && (repository == "" || hasScope(scopes, repository, action)) {
+			return true
+		}
+
+		challenge := fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, f.Server.URL)
+		if repository != "" {
+			challenge += fmt.Sprintf(`,scope="repository:%s:%s"`, repository, action)
+		}
+		w.Header().Set("WWW-Authenticate", challenge)
+	default:
+		return true
+	}
+
+	w.WriteHeader(http.StatusUnauthorized)
+	return false
+}
+
+func (f *Registry) serveToken(w http.ResponseWriter, r *http.Request) {
+	f.tokenRequests++
+
+	if f.user != nil {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != f.user.Name || password != f.user.Password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	token := fmt.Sprintf("token-%d", f.tokenRequests)
+	f.tokens[token] = r.URL.Query()["scope"]
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"token": token, "expires_in": f.ExpiresIn})
+}
+
+// hasScope returns true if one of the scopes grants the action on the repository
+func hasScope(scopes []string, repository string, action string) bool {
+	for _, scope := range scopes {
+		parts := strings.Split(scope, ":")
+		if len(parts) != 3 || parts[1] != repository {
+			continue
+		}
+		for _, a := range strings.Split(parts[2], ",") {
+			if a == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *Registry) serveManifest(w http.ResponseWriter, r *http.Request, repository string, reference string) {
+	dgst, err := digest.Parse(reference)
+	if err != nil {
+		dgst = f.tags[repository][reference]
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		manifest, ok := f.manifests[repository][dgst]
+		if !ok || !strings.Contains(r.Header.Get("Accept"), manifest.mediaType) {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", manifest.mediaType)
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest.content)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest.content)
+		}
+	case http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		dgst := digest.FromBytes(content)
+
+		if f.manifests[repository] == nil {
+			f.manifests[repository] = make(map[digest.Digest]fakeManifest)
+			f.tags[repository] = make(map[string]digest.Digest)
+		}
+		f.manifests[repository][dgst] = fakeManifest{mediaType: r.Header.Get("Content-Type"), content: content}
+		if _, err := digest.Parse(reference); err != nil {
+			f.tags[repository][reference] = dgst
+		}
+
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if _, ok := f.manifests[repository][dgst]; !ok || reference != dgst.String() {
+			http.NotFound(w, r)
+			return
+		}
+
+		delete(f.manifests[repository], dgst)
+		for tag, tagged := range f.tags[repository] {
+			if tagged == dgst {
+				delete(f.tags[repository], tag)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (f *Registry) serveUpload(w http.ResponseWriter, r *http.Request, repository string, id string) {
+	switch r.Method {
+	case http.MethodPost:
+		query := r.URL.Query()
+		if mount, from := digest.Digest(query.Get("mount")), query.Get("from"); mount != "" {
+			if content, ok := f.blobs[from][mount]; ok {
+				if f.blobs[repository] == nil {
+					f.blobs[repository] = make(map[digest.Digest][]byte)
+				}
+				f.blobs[repository][mount] = content
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+		}
+
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = repository
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, id))
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		if f.uploads[id] != repository {
+			http.NotFound(w, r)
+			return
+		}
+
+		content, _ := io.ReadAll(r.Body)
+		dgst := digest.Digest(r.URL.Query().Get("digest"))
+		if digest.FromBytes(content) != dgst {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if f.blobs[repository] == nil {
+			f.blobs[repository] = make(map[digest.Digest][]byte)
+		}
+		f.blobs[repository][dgst] = content
+		delete(f.uploads, id)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		delete(f.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *Registry) serveBlob(w http.ResponseWriter, r *http.Request, repository string, dgst digest.Digest) {
+	content, ok := f.blobs[repository][dgst]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(content)
+	}
+}
+
+func (f *Registry) serveTags(w http.ResponseWriter, r *http.Request, repository string) {
+	if _, ok := f.tags[repository]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	var tags []string
+	last := r.URL.Query().Get("last")
+	for tag := range f.tags[repository] {
+		if tag > last {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	if f.PageSize > 0 && len(tags) > f.PageSize {
+		tags = tags[:f.PageSize]
+		w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=%d&last=%s>; rel="next"`, repository, f.PageSize, tags[len(tags)-1]))
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+}
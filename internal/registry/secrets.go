@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	envSecretPrefix  = "env:"
+	fileSecretPrefix = "file:"
+)
+
+// ResolveSecret resolves a value that refers to an environment variable (env:VAR) or a
+// file (file:/path). Any other value is returned as is.
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envSecretPrefix):
+		name := strings.TrimPrefix(value, envSecretPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("undefined environment variable: %s", name)
+		}
+		return trimNewline(secret), nil
+	case strings.HasPrefix(value, fileSecretPrefix):
+		return ReadSecretFile(strings.TrimPrefix(value, fileSecretPrefix))
+	}
+	return value, nil
+}
+
+// IsSecretReference returns true when the value refers to an environment variable (env:VAR) or a
+// file (file:/path) instead of holding the secret
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, envSecretPrefix) || strings.HasPrefix(value, fileSecretPrefix)
+}
+
+// ReadSecretFile reads a secret from a file, removing the trailing newline. A warning is
+// logged when the file can be read by any user.
+func ReadSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrap(err, "reading the secret file")
+	}
+
+	if info.Mode().Perm()&0004 != 0 {
+		log.Warnf("The secret file %s is readable by all users, consider restricting its permissions (i.e. chmod 600)", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "reading the secret file")
+	}
+
+	return trimNewline(string(content)), nil
+}
+
+// ReadSecret reads a secret from the reader (i.e. stdin), removing the trailing newline
+func ReadSecret(r io.Reader) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", errors.Wrap(err, "reading the secret")
+	}
+	return trimNewline(string(content)), nil
+}
+
+// trimNewline removes the trailing newlines added by editors and echo
+func trimNewline(value string) string {
+	return strings.TrimRight(value, "\r\n")
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(secretFile, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TUGBOAT_TEST_SECRET", "env-secret\n")
+
+	testCases := []struct {
+		name        string
+		value       string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "plain value", value: "plain", expected: "plain"},
+		{name: "environment variable", value: "env:TUGBOAT_TEST_SECRET", expected: "env-secret"},
+		{name: "undefined environment variable", value: "env:TUGBOAT_TEST_UNDEFINED", expectedErr: true},
+		{name: "file", value: "file:" + secretFile, expected: "file-secret"},
+		{name: "missing file", value: "file:" + filepath.Join(dir, "missing"), expectedErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ResolveSecret(tc.value)
+
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", actual)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("An unexpected error occurred: %v", err)
+			}
+
+			if tc.expected != actual {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadSecret(t *testing.T) {
+	actual, err := ReadSecret(strings.NewReader("stdin-secret\r\n"))
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+
+	if actual != "stdin-secret" {
+		t.Errorf("expected stdin-secret, got %v", actual)
+	}
+}
+
+func TestIsSecretReference(t *testing.T) {
+	testCases := map[string]bool{
+		"env:REGISTRY_PASSWORD":         true,
+		"file:/run/secrets/password":    true,
+		"password":                      false,
+		"":                              false,
+		"environment:REGISTRY_PASSWORD": false,
+	}
+	for value, expected := range testCases {
+		if actual := IsSecretReference(value); actual != expected {
+			t.Errorf("%v: expected %v, got %v", value, expected, actual)
+		}
+	}
+}
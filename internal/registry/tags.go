@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type tagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// ListTags returns every tag of the repository, following the pagination of the registry
+func (c *Client) ListTags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+
+	path := fmt.Sprintf("/v2/%s/tags/list", repository)
+	for path != "" {
+		resp, err := c.do(ctx, &request{
+			method: http.MethodGet,
+			path:   path,
+			scopes: []string{repositoryScope(repository, "pull")},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkResponse(resp, http.StatusOK); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var list tagList
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding the tags of %s", repository)
+		}
+		tags = append(tags, list.Tags...)
+
+		path, err = nextLink(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+// nextLink returns the url of the next page from the Link header (i.e. </v2/ns/app/tags/list?last=b&n=2>; rel="next")
+func nextLink(resp *http.Response) (string, error) {
+	header := resp.Header.Get("Link")
+	if header == "" {
+		return "", nil
+	}
+
+	for _, link := range strings.Split(header, ",") {
+		target, params, _ := strings.Cut(strings.TrimSpace(link), ";")
+		if !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+
+		next, err := resp.Request.URL.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return "", errors.Wrap(err, "parsing the next page link")
+		}
+		return next.String(), nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClient_ListTags(t *testing.T) {
+	user := &RegistryUser{Name: "user", Password: "password"}
+	fake := newFakeRegistry(t, "basic", user)
+	fake.PageSize = 2
+
+	for _, tag := range []string{"1.0.0", "1.1.0", "2.0.0", "latest", "stable"} {
+		fake.PutImage("ns/app", tag, "amd64")
+	}
+
+	tags, err := fake.client(user).ListTags(context.Background(), "ns/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "1.0.0 1.1.0 2.0.0 latest stable"
+	if actual := strings.Join(tags, " "); expected != actual {
+		t.Errorf("expected '%v', got '%v'", expected, actual)
+	}
+
+	if actual := fake.CountRequests("GET /v2/ns/app/tags/list"); actual != 4 {
+		t.Errorf("expected 3 pages and a challenged request, got %v requests", actual)
+	}
+
+	if _, err := fake.client(user).ListTags(context.Background(), "ns/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
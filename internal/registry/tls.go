@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// TLSOptions configures how the connection to a registry is secured
+type TLSOptions struct {
+	// Insecure allows plain http and skips the verification of the registry certificate
+	Insecure bool
+	// CaFile is a PEM encoded certificate authority trusted in addition to the system ones
+	CaFile string
+	// ClientCert and ClientKey are the PEM encoded certificate and key used to authenticate the client
+	ClientCert string
+	ClientKey  string
+}
+
+// IsDefault returns true when no tls options are set
+func (o TLSOptions) IsDefault() bool {
+	return o == TLSOptions{}
+}
+
+// Config creates the tls configuration, reading the certificates from their files
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.Insecure,
+	}
+
+	if o.CaFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(o.CaFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading the registry ca file")
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates were found in %s", o.CaFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, errors.New("both the registry client certificate and key must be provided")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading the registry client certificate")
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// httpClient creates a http client using the tls options
+func (o TLSOptions) httpClient() (*http.Client, error) {
+	config, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+
+	return &http.Client{Transport: transport}, nil
+}
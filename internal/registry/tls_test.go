@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTLSOptions_Config(t *testing.T) {
+	fake := newFakeRegistry(t, "", nil)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: fake.Server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certificate, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		opts      TLSOptions
+		expectErr bool
+	}{
+		{name: "untrusted certificate", opts: TLSOptions{}, expectErr: true},
+		{name: "ca file", opts: TLSOptions{CaFile: caFile}},
+		{name: "insecure", opts: TLSOptions{Insecure: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewClient(fake.Host(), &ClientOptions{TLS: tc.opts}).Ping(context.Background())
+			if tc.expectErr != (err != nil) {
+				t.Errorf("expected an error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+
+	if _, err := (TLSOptions{CaFile: filepath.Join(t.TempDir(), "missing.pem")}).Config(); err == nil {
+		t.Error("expected an error for a missing ca file")
+	}
+
+	if _, err := (TLSOptions{ClientCert: caFile}).Config(); err == nil {
+		t.Error("expected an error when the client key is missing")
+	}
+}
+
+func TestClient_plainHttp(t *testing.T) {
+	fake := newFakeRegistry(t, "", nil)
+	fake.PutImage("ns/app", "1.0.0", "amd64")
+
+	server := httptest.NewServer(fake.Server.Config.Handler)
+	defer server.Close()
+	host := server.Listener.Addr().String()
+
+	ctx := context.Background()
+
+	// plain http is used when requested by the url
+	if _, err := NewClient("http://"+host, nil).GetManifest(ctx, "ns/app", "1.0.0"); err != nil {
+		t.Errorf("An unexpected error occurred: %v", err)
+	}
+
+	// insecure registries fall back to plain http
+	if _, err := NewClient(host, &ClientOptions{TLS: TLSOptions{Insecure: true}}).GetManifest(ctx, "ns/app", "1.0.0"); err != nil {
+		t.Errorf("An unexpected error occurred: %v", err)
+	}
+
+	// secure registries are only reached over https
+	if err := NewClient(host, nil).Ping(ctx); err == nil {
+		t.Error("expected an error when the registry is not served over https")
+	}
+}